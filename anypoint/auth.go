@@ -0,0 +1,290 @@
+package anypoint
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+const OAUTH2_GRANT_TYPE_JWT_BEARER = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+const OAUTH2_GRANT_TYPE_TOKEN_EXCHANGE = "urn:ietf:params:oauth:grant-type:token-exchange"
+const OAUTH2_TOKEN_TYPE_JWT = "urn:ietf:params:oauth:token-type:jwt"
+const OAUTH2_TOKEN_TYPE_ACCESS_TOKEN = "urn:ietf:params:oauth:token-type:access_token"
+
+// the validity of the JWT bearer assertions signed by the provider
+const JWT_ASSERTION_VALIDITY = 3 * time.Minute
+
+// the anypoint platform's oauth2 token endpoint path
+const OAUTH2_TOKEN_PATH = "/accounts/api/v2/oauth2/token"
+
+// token endpoint response for the grants not covered by the anypoint client library
+type oauth2TokenResponse struct {
+	AccessToken     string `json:"access_token"`
+	TokenType       string `json:"token_type"`
+	ExpiresIn       int    `json:"expires_in"`
+	IssuedTokenType string `json:"issued_token_type"`
+}
+
+func (r *oauth2TokenResponse) GetAccessToken() string {
+	return r.AccessToken
+}
+
+/*
+Authenticates a connected app using the JWT bearer grant.
+The assertion is signed using the given PEM private key whose certificate is registered in the connected app's public keys.
+*/
+func jwtBearerAuth(ctx context.Context, server_index int, client_id string, client_secret string, subject string, private_key string) (*oauth2TokenResponse, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	authres, err := requestJWTBearerToken(ctx, server_index, client_id, client_secret, subject, private_key)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to Authenticate Using Connected App JWT Bearer",
+			Detail:   err.Error(),
+		})
+		return &oauth2TokenResponse{}, diags
+	}
+	return authres, diags
+}
+
+func requestJWTBearerToken(ctx context.Context, server_index int, client_id string, client_secret string, subject string, private_key string) (*oauth2TokenResponse, error) {
+	tokenurl, err := oauth2TokenUrl(server_index)
+	if err != nil {
+		return nil, err
+	}
+	key, err := parseJWTPrivateKey(private_key)
+	if err != nil {
+		return nil, err
+	}
+	jti, err := genJWTId()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	claims := map[string]interface{}{
+		"iss": client_id,
+		"sub": subject,
+		"aud": tokenurl,
+		"iat": now.Unix(),
+		"nbf": now.Unix(),
+		"exp": now.Add(JWT_ASSERTION_VALIDITY).Unix(),
+		"jti": jti,
+	}
+	assertion, err := signJWTAssertion(key, claims)
+	if err != nil {
+		return nil, err
+	}
+	form := url.Values{}
+	form.Set("grant_type", OAUTH2_GRANT_TYPE_JWT_BEARER)
+	form.Set("assertion", assertion)
+	form.Set("client_id", client_id)
+	if client_secret != "" {
+		form.Set("client_secret", client_secret)
+	}
+	return requestOAuth2Token(ctx, tokenurl, form)
+}
+
+/*
+Authenticates a connected app by exchanging an OIDC identity token issued by a trusted identity provider (a CI platform for instance)
+for an anypoint access token.
+*/
+func oidcTokenExchangeAuth(ctx context.Context, server_index int, client_id string, client_secret string, id_token string) (*oauth2TokenResponse, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	authres, err := requestTokenExchangeToken(ctx, server_index, client_id, client_secret, id_token)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to Authenticate Using OIDC Token Exchange",
+			Detail:   err.Error(),
+		})
+		return &oauth2TokenResponse{}, diags
+	}
+	return authres, diags
+}
+
+func requestTokenExchangeToken(ctx context.Context, server_index int, client_id string, client_secret string, id_token string) (*oauth2TokenResponse, error) {
+	tokenurl, err := oauth2TokenUrl(server_index)
+	if err != nil {
+		return nil, err
+	}
+	form := url.Values{}
+	form.Set("grant_type", OAUTH2_GRANT_TYPE_TOKEN_EXCHANGE)
+	form.Set("subject_token", strings.TrimSpace(id_token))
+	form.Set("subject_token_type", OAUTH2_TOKEN_TYPE_JWT)
+	form.Set("requested_token_type", OAUTH2_TOKEN_TYPE_ACCESS_TOKEN)
+	form.Set("client_id", client_id)
+	if client_secret != "" {
+		form.Set("client_secret", client_secret)
+	}
+	return requestOAuth2Token(ctx, tokenurl, form)
+}
+
+func oauth2TokenUrl(server_index int) (string, error) {
+	if server_index < 0 || server_index >= len(anypointBaseUrls) {
+		return "", fmt.Errorf("unknown control plane server index %d", server_index)
+	}
+	return anypointBaseUrls[server_index] + OAUTH2_TOKEN_PATH, nil
+}
+
+// posts the given form to the token endpoint and decodes the issued token
+func requestOAuth2Token(ctx context.Context, tokenurl string, form url.Values) (*oauth2TokenResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenurl, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	httpr, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer httpr.Body.Close()
+	b, err := ioutil.ReadAll(httpr.Body)
+	if err != nil {
+		return nil, err
+	}
+	if httpr.StatusCode >= 300 {
+		return nil, fmt.Errorf("token request failed with status %d\n details: %s", httpr.StatusCode, string(b))
+	}
+	var authres oauth2TokenResponse
+	if err := json.Unmarshal(b, &authres); err != nil {
+		return nil, fmt.Errorf("unable to decode token response: %s", err)
+	}
+	if authres.AccessToken == "" {
+		return nil, fmt.Errorf("the token response contains no access token")
+	}
+	return &authres, nil
+}
+
+// parses a PEM encoded RSA or ECDSA private key in PKCS#1, SEC 1 or PKCS#8 format
+func parseJWTPrivateKey(private_key string) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(strings.TrimSpace(private_key)))
+	if block == nil {
+		return nil, fmt.Errorf("the private key is not PEM encoded")
+	}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		switch k := key.(type) {
+		case *rsa.PrivateKey:
+			return k, nil
+		case *ecdsa.PrivateKey:
+			return k, nil
+		}
+		return nil, fmt.Errorf("unsupported private key type %T, only RSA and ECDSA keys are supported", key)
+	}
+	return nil, fmt.Errorf("unsupported PEM block type %s", block.Type)
+}
+
+// signs the given claims as a compact JWS using RS256 for RSA keys and ES256/ES384/ES512 for ECDSA keys
+func signJWTAssertion(key crypto.Signer, claims map[string]interface{}) (string, error) {
+	var alg string
+	var hash crypto.Hash
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		alg, hash = "RS256", crypto.SHA256
+	case *ecdsa.PrivateKey:
+		switch k.Curve.Params().BitSize {
+		case 256:
+			alg, hash = "ES256", crypto.SHA256
+		case 384:
+			alg, hash = "ES384", crypto.SHA384
+		case 521:
+			alg, hash = "ES512", crypto.SHA512
+		default:
+			return "", fmt.Errorf("unsupported ECDSA curve %s", k.Curve.Params().Name)
+		}
+	default:
+		return "", fmt.Errorf("unsupported private key type %T", key)
+	}
+	header, err := json.Marshal(map[string]string{"alg": alg, "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signinginput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	h := hash.New()
+	h.Write([]byte(signinginput))
+	digest := h.Sum(nil)
+
+	var signature []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		signature, err = rsa.SignPKCS1v15(rand.Reader, k, hash, digest)
+		if err != nil {
+			return "", err
+		}
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest)
+		if err != nil {
+			return "", err
+		}
+		// JWS uses the fixed size concatenation of r and s instead of ASN.1
+		size := (k.Curve.Params().BitSize + 7) / 8
+		signature = make([]byte, 2*size)
+		r.FillBytes(signature[:size])
+		s.FillBytes(signature[size:])
+	}
+	return signinginput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func genJWTId() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+/*
+Returns the given value if set, otherwise reads the content of the given file.
+Used by provider arguments that can be given either inline or as a file path.
+*/
+func loadProviderSecret(value string, file string) (string, error) {
+	if value != "" || file == "" {
+		return value, nil
+	}
+	b, err := ioutil.ReadFile(expandHomePath(file))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// replaces a leading ~ in the given path by the user's home directory
+func expandHomePath(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return home + path[1:]
+}
@@ -0,0 +1,118 @@
+package anypoint
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// the credentials file read when the provider's credentials_file is not set
+const DEFAULT_CREDENTIALS_FILE = "~/.anypoint/credentials"
+
+// the profile used when the provider's profile is not set
+const DEFAULT_PROFILE = "default"
+
+// the keys accepted in a credentials file profile
+var CREDENTIALS_PROFILE_KEYS = []string{
+	"client_id",
+	"client_secret",
+	"username",
+	"password",
+	"jwt_private_key_file",
+	"jwt_subject",
+	"cplane",
+	"org_id",
+}
+
+/*
+Loads the credentials file, an INI like file where each section is a named profile:
+
+	[default]
+	client_id     = xxx
+	client_secret = xxx
+	cplane        = eu
+	org_id        = xxx
+
+Lines starting with # or ; are comments.
+Returns the profiles indexed by name.
+*/
+func loadCredentialsFile(path string) (map[string]map[string]string, error) {
+	f, err := os.Open(expandHomePath(path))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	profiles := make(map[string]map[string]string)
+	var current map[string]string
+	scanner := bufio.NewScanner(f)
+	lineno := 0
+	for scanner.Scan() {
+		lineno++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("%s:%d: malformed profile header %q", path, lineno, line)
+			}
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			if name == "" {
+				return nil, fmt.Errorf("%s:%d: empty profile name", path, lineno)
+			}
+			if _, ok := profiles[name]; !ok {
+				profiles[name] = make(map[string]string)
+			}
+			current = profiles[name]
+			continue
+		}
+		kv := strings.SplitN(line, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("%s:%d: expected key = value, got %q", path, lineno, line)
+		}
+		if current == nil {
+			return nil, fmt.Errorf("%s:%d: %q is not part of any profile", path, lineno, line)
+		}
+		key := strings.TrimSpace(kv[0])
+		if !StringInSlice(CREDENTIALS_PROFILE_KEYS, key, false) {
+			return nil, fmt.Errorf("%s:%d: unknown key %q, accepted keys are: %s", path, lineno, key, strings.Join(CREDENTIALS_PROFILE_KEYS, ", "))
+		}
+		current[key] = strings.Trim(strings.TrimSpace(kv[1]), `"'`)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
+
+/*
+Returns the profile to use from the credentials file.
+When the profile is explicitly selected, both the file and the profile must exist.
+Otherwise the default profile is returned if available, nil if not.
+*/
+func loadCredentialsProfile(path string, profile string) (map[string]string, error) {
+	explicit := profile != "" || path != ""
+	if path == "" {
+		path = DEFAULT_CREDENTIALS_FILE
+	}
+	if profile == "" {
+		profile = DEFAULT_PROFILE
+	}
+	profiles, err := loadCredentialsFile(path)
+	if err != nil {
+		if os.IsNotExist(err) && !explicit {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unable to load credentials file %s: %s", path, err)
+	}
+	p, ok := profiles[profile]
+	if !ok {
+		if !explicit {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("profile %q not found in credentials file %s", profile, path)
+	}
+	return p, nil
+}
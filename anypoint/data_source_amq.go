@@ -61,7 +61,7 @@ func dataSourceAMQ() *schema.Resource {
 							Type:        schema.TypeInt,
 							Optional:    true,
 							Default:     20,
-							Description: "Limit the number of elements in the response.",
+							Description: "Limit the number of elements per request, all pages are loaded automatically.",
 						},
 						"starts_with": {
 							Type:        schema.TypeString,
@@ -72,7 +72,9 @@ func dataSourceAMQ() *schema.Resource {
 							Type:        schema.TypeList,
 							Optional:    true,
 							Description: "Includes only results with the given Ids.",
-							Elem:        schema.TypeString,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
 						},
 					},
 				},
@@ -153,23 +155,33 @@ func dataSourceAMQRead(ctx context.Context, d *schema.ResourceData, m interface{
 		diags = append(diags, errDiags...)
 		return diags
 	}
-	//Executing Request
-	res, httpr, err := req.Execute()
-	defer httpr.Body.Close()
-	if err != nil {
-		var details string
-		if httpr != nil {
-			b, _ := ioutil.ReadAll(httpr.Body)
-			details = string(b)
-		} else {
-			details = err.Error()
+	//Executing Request for all pages
+	offset, limit := getPaginationParams(searchopts, 20)
+	res := make([]amq.Queue, 0)
+	for {
+		page, httpr, err := req.Offset(int32(offset)).Limit(int32(limit)).Execute()
+		if err != nil {
+			var details string
+			if httpr != nil {
+				b, _ := ioutil.ReadAll(httpr.Body)
+				details = string(b)
+			} else {
+				details = err.Error()
+			}
+			diags := append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Unable to Get AMQs",
+				Detail:   details,
+			})
+			return diags
+		}
+		httpr.Body.Close()
+		res = append(res, page...)
+		offset += len(page)
+		// the destinations list does not provide the total number of results
+		if len(page) < limit {
+			break
 		}
-		diags := append(diags, diag.Diagnostic{
-			Severity: diag.Error,
-			Summary:  "Unable to Get AMQs",
-			Detail:   details,
-		})
-		return diags
 	}
 	//process data
 	amqinstance := flattenAMQsData(&res)
@@ -202,20 +214,12 @@ func parseAMQSearchOpts(req amq.DefaultApiApiGetAMQListRequest, params *schema.S
 	opts := params.List()[0]
 
 	for k, v := range opts.(map[string]interface{}) {
-		if k == "offset" {
-			req = req.Offset(v.(int32))
-			continue
-		}
-		if k == "limit" {
-			req = req.Limit(v.(int32))
-			continue
-		}
-		if k == "starts_with" {
+		if k == "starts_with" && len(v.(string)) > 0 {
 			req = req.StartsWith(v.(string))
 			continue
 		}
-		if k == "destination_ids" {
-			req = req.DestinationIds(v.([]string))
+		if k == "destination_ids" && len(v.([]interface{})) > 0 {
+			req = req.DestinationIds(ListInterface2ListStrings(v.([]interface{})))
 			continue
 		}
 	}
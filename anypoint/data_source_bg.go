@@ -841,6 +841,7 @@ func getBGUpdatableAttributes() []string {
 		"name", "owner_id", "entitlements_createenvironments", "entitlements_createsuborgs",
 		"entitlements_globaldeployment", "entitlements_vcoresproduction_assigned", "entitlements_vcoressandbox_assigned",
 		"entitlements_vcoresdesign_assigned", "entitlements_vpcs_assigned", "entitlements_loadbalancer_assigned", "entitlements_vpns_assigned",
+		"entitlements_staticips_assigned", "entitlements",
 	}
 	return attributes[:]
 }
@@ -0,0 +1,308 @@
+package anypoint
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	org "github.com/mulesoft-anypoint/anypoint-client-go/org"
+)
+
+// the business group tree's path separator
+const BG_PATH_SEPARATOR = "/"
+
+// criteria the business groups are matched against
+type bgsFilter struct {
+	nameRegex   *regexp.Regexp
+	minDepth    int
+	maxDepth    int
+	isFederated *bool
+	ownerId     string
+}
+
+func dataSourceBGs() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceBGsRead,
+		Description: `
+		Walks the business group tree from the given root and reads the business groups matching the filters.
+		`,
+		Schema: map[string]*schema.Schema{
+			"org_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The business group id to start the walk from.",
+			},
+			"name_regex": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Description:      "Only the business groups which name matches this regular expression are returned.",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsValidRegExp),
+			},
+			"min_depth": {
+				Type:             schema.TypeInt,
+				Optional:         true,
+				Default:          0,
+				Description:      "The minimum depth, relative to the root, of the returned business groups. Use 1 to exclude the root.",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(0)),
+			},
+			"max_depth": {
+				Type:             schema.TypeInt,
+				Optional:         true,
+				Default:          0,
+				Description:      "The maximum depth, relative to the root, of the returned business groups, 0 means the whole tree.",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(0)),
+			},
+			"is_federated": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "If set, only the business groups which federation status matches are returned.",
+			},
+			"owner_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "If set, only the business groups owned by this user are returned.",
+			},
+			"ids": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The ids of the matching business groups.",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"business_groups": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The matching business groups in depth-first order.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The business group id.",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The business group name.",
+						},
+						"path": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The names of the business group's ancestors from the root of the walk and its own name separated by /.",
+						},
+						"parent_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The id of the immediate parent business group, empty for the root of the walk.",
+						},
+						"depth": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The depth of the business group relative to the root of the walk.",
+						},
+						"is_federated": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Whether the business group is federated.",
+						},
+						"owner_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The business group owner id.",
+						},
+						"environments": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "The environments of the business group.",
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"id": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "The environment id.",
+									},
+									"name": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "The environment name.",
+									},
+									"type": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "The environment type.",
+									},
+									"is_production": {
+										Type:        schema.TypeBool,
+										Computed:    true,
+										Description: "Whether the environment is a production environment.",
+									},
+									"client_id": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "The environment client id.",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"len": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The number of matching business groups",
+			},
+		},
+	}
+}
+
+func dataSourceBGsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	pco := m.(ProviderConfOutput)
+	orgid := d.Get("org_id").(string)
+
+	filter := &bgsFilter{
+		minDepth: d.Get("min_depth").(int),
+		maxDepth: d.Get("max_depth").(int),
+		ownerId:  d.Get("owner_id").(string),
+	}
+	if v := d.Get("name_regex").(string); v != "" {
+		filter.nameRegex = regexp.MustCompile(v)
+	}
+	// is_federated is a tri-state filter, false must be told apart from unset
+	if !d.GetRawConfig().GetAttr("is_federated").IsNull() {
+		v := d.Get("is_federated").(bool)
+		filter.isFederated = &v
+	}
+
+	tree, err := getBGTree(ctx, &pco, orgid)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to get Business Groups under " + orgid,
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	list := make([]interface{}, 0)
+	collectBGs(tree, orgid, "", "", 0, filter, &list)
+	ids := make([]string, len(list))
+	for i, item := range list {
+		ids[i] = item.(map[string]interface{})["id"].(string)
+	}
+
+	if err := d.Set("business_groups", list); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to set Business Groups under " + orgid,
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	d.Set("ids", ids)
+	d.Set("len", len(list))
+	d.SetId(orgid)
+
+	return diags
+}
+
+/*
+Walks the business group tree depth-first from the given business group
+and appends the business groups matching the filter to the list.
+*/
+func collectBGs(tree *bgTree, orgid string, parentid string, parentpath string, depth int, filter *bgsFilter, list *[]interface{}) {
+	res := tree.orgs[orgid]
+	path := res.GetName()
+	if parentpath != "" {
+		path = parentpath + BG_PATH_SEPARATOR + path
+	}
+	if filter.matches(res, depth) {
+		item := flattenBGsItem(res)
+		item["path"] = path
+		item["parent_id"] = parentid
+		item["depth"] = depth
+		*list = append(*list, item)
+	}
+
+	if filter.maxDepth != 0 && depth >= filter.maxDepth {
+		return
+	}
+	for _, subid := range tree.children[orgid] {
+		collectBGs(tree, subid, orgid, path, depth+1, filter, list)
+	}
+}
+
+// the business groups of a hierarchy, each one fetched once, and the direct children of each business group
+type bgTree struct {
+	orgs     map[string]*org.MasterBGDetail
+	children map[string][]string
+}
+
+/*
+Fetches the given business group and all its descendants once.
+The sub organization ids list all the descendants, the direct children are resolved from each descendant's immediate parent.
+*/
+func getBGTree(ctx context.Context, pco *ProviderConfOutput, orgid string) (*bgTree, error) {
+	authctx := getBGAuthCtx(ctx, pco)
+	root, err := getOrg(authctx, pco, orgid)
+	if err != nil {
+		return nil, err
+	}
+	tree := &bgTree{
+		orgs:     map[string]*org.MasterBGDetail{orgid: root},
+		children: make(map[string][]string),
+	}
+	for _, subid := range root.GetSubOrganizationIds() {
+		sub, err := getOrg(authctx, pco, subid)
+		if err != nil {
+			return nil, err
+		}
+		tree.orgs[subid] = sub
+		ancestors := sub.GetParentOrganizationIds()
+		if len(ancestors) == 0 {
+			continue
+		}
+		parentid := ancestors[len(ancestors)-1]
+		tree.children[parentid] = append(tree.children[parentid], subid)
+	}
+	return tree, nil
+}
+
+func (f *bgsFilter) matches(bg *org.MasterBGDetail, depth int) bool {
+	if depth < f.minDepth || (f.maxDepth != 0 && depth > f.maxDepth) {
+		return false
+	}
+	if f.nameRegex != nil && !f.nameRegex.MatchString(bg.GetName()) {
+		return false
+	}
+	if f.isFederated != nil && bg.GetIsFederated() != *f.isFederated {
+		return false
+	}
+	if f.ownerId != "" && bg.GetOwnerId() != f.ownerId {
+		return false
+	}
+	return true
+}
+
+func flattenBGsItem(bg *org.MasterBGDetail) map[string]interface{} {
+	environments := make([]interface{}, len(bg.GetEnvironments()))
+	for i, e := range bg.GetEnvironments() {
+		environments[i] = map[string]interface{}{
+			"id":            e.GetId(),
+			"name":          e.GetName(),
+			"type":          e.GetType(),
+			"is_production": e.GetIsProduction(),
+			"client_id":     e.GetClientId(),
+		}
+	}
+	return map[string]interface{}{
+		"id":           bg.GetId(),
+		"name":         bg.GetName(),
+		"is_federated": bg.GetIsFederated(),
+		"owner_id":     bg.GetOwnerId(),
+		"environments": environments,
+	}
+}
@@ -0,0 +1,203 @@
+package anypoint
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// the context a connected app scope must be granted in
+const (
+	CONNECTED_APP_SCOPE_CTX_NONE = "none"
+	CONNECTED_APP_SCOPE_CTX_ORG  = "org"
+	CONNECTED_APP_SCOPE_CTX_ENV  = "env"
+)
+
+type connectedAppScopeDefinition struct {
+	Scope       string
+	Context     string
+	Description string
+}
+
+// catalog of the known anypoint connected app scopes.
+// It is maintained by hand from the scopes offered by Access Management when editing a connected app
+// and is not exhaustive, validating against it is therefore opt-in.
+var CONNECTED_APP_SCOPES_CATALOG = []connectedAppScopeDefinition{
+	// on behalf of user scopes
+	{"full", CONNECTED_APP_SCOPE_CTX_NONE, "Full access to the user's resources"},
+	{"read:full", CONNECTED_APP_SCOPE_CTX_NONE, "Read only access to the user's resources"},
+	{"openid", CONNECTED_APP_SCOPE_CTX_NONE, "OpenID Connect authentication"},
+	{"profile", CONNECTED_APP_SCOPE_CTX_NONE, "View the user's profile"},
+	{"email", CONNECTED_APP_SCOPE_CTX_NONE, "View the user's email"},
+	{"offline_access", CONNECTED_APP_SCOPE_CTX_NONE, "Issue refresh tokens"},
+	// organization scopes
+	{"aeh_admin", CONNECTED_APP_SCOPE_CTX_ORG, "API Experience Hub administrator"},
+	{"read:audit_logs", CONNECTED_APP_SCOPE_CTX_ORG, "View audit logs"},
+	{"view:organization", CONNECTED_APP_SCOPE_CTX_ORG, "View the business group"},
+	{"edit:organization", CONNECTED_APP_SCOPE_CTX_ORG, "Manage the business group"},
+	{"create:suborgs", CONNECTED_APP_SCOPE_CTX_ORG, "Create business groups"},
+	{"create:environment", CONNECTED_APP_SCOPE_CTX_ORG, "Create environments"},
+	{"read:usage", CONNECTED_APP_SCOPE_CTX_ORG, "View usage reports"},
+	{"admin:usage", CONNECTED_APP_SCOPE_CTX_ORG, "Manage usage reports"},
+	{"read:users", CONNECTED_APP_SCOPE_CTX_ORG, "View users"},
+	{"edit:users", CONNECTED_APP_SCOPE_CTX_ORG, "Manage users"},
+	{"read:teams", CONNECTED_APP_SCOPE_CTX_ORG, "View teams"},
+	{"edit:teams", CONNECTED_APP_SCOPE_CTX_ORG, "Manage teams"},
+	{"edit:identity_providers", CONNECTED_APP_SCOPE_CTX_ORG, "Manage identity providers"},
+	{"manage:client_applications", CONNECTED_APP_SCOPE_CTX_ORG, "Manage connected apps"},
+	{"exchange_viewer", CONNECTED_APP_SCOPE_CTX_ORG, "Exchange viewer"},
+	{"exchange_contributor", CONNECTED_APP_SCOPE_CTX_ORG, "Exchange contributor"},
+	{"exchange_creator", CONNECTED_APP_SCOPE_CTX_ORG, "Exchange creator"},
+	{"exchange_administrator", CONNECTED_APP_SCOPE_CTX_ORG, "Exchange administrator"},
+	{"design_center:api_designer:read", CONNECTED_APP_SCOPE_CTX_ORG, "View Design Center projects"},
+	{"design_center:api_designer:write", CONNECTED_APP_SCOPE_CTX_ORG, "Manage Design Center projects"},
+	{"read:cloudhub_networking", CONNECTED_APP_SCOPE_CTX_ORG, "View CloudHub networking (VPCs, VPNs, load balancers)"},
+	{"manage:cloudhub_networking", CONNECTED_APP_SCOPE_CTX_ORG, "Manage CloudHub networking (VPCs, VPNs, load balancers)"},
+	{"read:runtime_fabrics", CONNECTED_APP_SCOPE_CTX_ORG, "View Runtime Fabrics"},
+	{"manage:runtime_fabrics", CONNECTED_APP_SCOPE_CTX_ORG, "Manage Runtime Fabrics"},
+	// environment scopes
+	{"view:environment", CONNECTED_APP_SCOPE_CTX_ENV, "View the environment"},
+	{"edit:environment", CONNECTED_APP_SCOPE_CTX_ENV, "Manage the environment"},
+	{"read:applications", CONNECTED_APP_SCOPE_CTX_ENV, "View Runtime Manager applications"},
+	{"create:applications", CONNECTED_APP_SCOPE_CTX_ENV, "Create Runtime Manager applications"},
+	{"manage:applications", CONNECTED_APP_SCOPE_CTX_ENV, "Manage Runtime Manager applications"},
+	{"delete:applications", CONNECTED_APP_SCOPE_CTX_ENV, "Delete Runtime Manager applications"},
+	{"read:servers", CONNECTED_APP_SCOPE_CTX_ENV, "View servers"},
+	{"manage:servers", CONNECTED_APP_SCOPE_CTX_ENV, "Manage servers"},
+	{"read:alerts", CONNECTED_APP_SCOPE_CTX_ENV, "View alerts"},
+	{"manage:alerts", CONNECTED_APP_SCOPE_CTX_ENV, "Manage alerts"},
+	{"read:api_configuration", CONNECTED_APP_SCOPE_CTX_ENV, "View API Manager configuration"},
+	{"manage:api_configuration", CONNECTED_APP_SCOPE_CTX_ENV, "Manage API Manager configuration"},
+	{"manage:api_policies", CONNECTED_APP_SCOPE_CTX_ENV, "Manage API Manager policies"},
+	{"read:destinations", CONNECTED_APP_SCOPE_CTX_ENV, "View Anypoint MQ destinations"},
+	{"manage:destinations", CONNECTED_APP_SCOPE_CTX_ENV, "Manage Anypoint MQ destinations"},
+	{"manage:clients", CONNECTED_APP_SCOPE_CTX_ENV, "Manage Anypoint MQ client apps"},
+	{"read:stats", CONNECTED_APP_SCOPE_CTX_ENV, "View Anypoint MQ stats"},
+	{"read:secrets", CONNECTED_APP_SCOPE_CTX_ENV, "View Secrets Manager secret groups"},
+	{"manage:secrets", CONNECTED_APP_SCOPE_CTX_ENV, "Manage Secrets Manager secret groups"},
+}
+
+func dataSourceConnectedAppScopes() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceConnectedAppScopesRead,
+		Description: `
+		Lists the catalog of ` + "`" + `connected app` + "`" + ` scopes known by this provider along with the context they must be granted in.
+		The catalog is maintained by hand and may not list every scope offered by the platform.
+		`,
+		Schema: map[string]*schema.Schema{
+			"context": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Description:      "Only returns the scopes requiring the given context. Enum values: none, org, env",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice([]string{CONNECTED_APP_SCOPE_CTX_NONE, CONNECTED_APP_SCOPE_CTX_ORG, CONNECTED_APP_SCOPE_CTX_ENV}, false)),
+			},
+			"scopes": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The list of known scopes",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"scope": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The name of the scope",
+						},
+						"context": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The context the scope must be granted in. none: no org_id nor env_id, org: org_id only, env: org_id and env_id",
+						},
+						"description": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The description of the scope",
+						},
+					},
+				},
+			},
+			"len": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The number of loaded results",
+			},
+		},
+	}
+}
+
+func dataSourceConnectedAppScopesRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	scopectx := d.Get("context").(string)
+
+	list := make([]interface{}, 0)
+	for _, def := range CONNECTED_APP_SCOPES_CATALOG {
+		if scopectx != "" && def.Context != scopectx {
+			continue
+		}
+		list = append(list, map[string]interface{}{
+			"scope":       def.Scope,
+			"context":     def.Context,
+			"description": def.Description,
+		})
+	}
+
+	if err := d.Set("scopes", list); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to set connected app scopes",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	d.Set("len", len(list))
+	d.SetId(strconv.FormatInt(time.Now().Unix(), 10))
+
+	return diags
+}
+
+// returns the definition of the given scope from the catalog, nil if unknown
+func getConnectedAppScopeDefinition(scope string) *connectedAppScopeDefinition {
+	for i, def := range CONNECTED_APP_SCOPES_CATALOG {
+		if strings.EqualFold(def.Scope, scope) {
+			return &CONNECTED_APP_SCOPES_CATALOG[i]
+		}
+	}
+	return nil
+}
+
+// verifies the given scope is known and that its context matches the catalog's requirements
+func validateConnectedAppScope(scope, orgid, envid string) error {
+	def := getConnectedAppScopeDefinition(scope)
+	if def == nil {
+		known := make([]string, len(CONNECTED_APP_SCOPES_CATALOG))
+		for i, d := range CONNECTED_APP_SCOPES_CATALOG {
+			known[i] = d.Scope
+		}
+		sort.Strings(known)
+		return fmt.Errorf("unknown connected app scope %q, known scopes are: %s", scope, strings.Join(known, ", "))
+	}
+	switch def.Context {
+	case CONNECTED_APP_SCOPE_CTX_NONE:
+		if orgid != "" || envid != "" {
+			return fmt.Errorf("scope %q does not accept any org_id nor env_id", scope)
+		}
+	case CONNECTED_APP_SCOPE_CTX_ORG:
+		if orgid == "" {
+			return fmt.Errorf("scope %q requires an org_id", scope)
+		}
+		if envid != "" {
+			return fmt.Errorf("scope %q does not accept an env_id", scope)
+		}
+	case CONNECTED_APP_SCOPE_CTX_ENV:
+		if orgid == "" || envid == "" {
+			return fmt.Errorf("scope %q requires both org_id and env_id", scope)
+		}
+	}
+	return nil
+}
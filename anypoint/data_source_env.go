@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io/ioutil"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -14,13 +15,15 @@ func dataSourceENV() *schema.Resource {
 	return &schema.Resource{
 		ReadContext: dataSourceENVRead,
 		Description: `
-		Reads all ` + "`" + `environments` + "`" + ` in your business group.
+		Reads an ` + "`" + `environment` + "`" + ` of your business group by id or by name.
 		`,
 		Schema: map[string]*schema.Schema{
 			"id": {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: "The unique id of this environment generated by the anypoint platform.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"id", "name"},
+				Description:  "The unique id of this environment generated by the anypoint platform.",
 			},
 			"org_id": {
 				Type:        schema.TypeString,
@@ -28,9 +31,11 @@ func dataSourceENV() *schema.Resource {
 				Description: "The organization id where the environment is defined.",
 			},
 			"name": {
-				Type:        schema.TypeString,
-				Computed:    true,
-				Description: "The name of the environment",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"id", "name"},
+				Description:  "The name of the environment, can be used instead of the id to look the environment up.",
 			},
 			"is_production": {
 				Type:        schema.TypeBool,
@@ -58,6 +63,20 @@ func dataSourceENVRead(ctx context.Context, d *schema.ResourceData, m interface{
 	orgid := d.Get("org_id").(string)
 	authctx := getENVAuthCtx(ctx, &pco)
 
+	if envid == "" {
+		name := d.Get("name").(string)
+		id, err := getENVIdByName(ctx, &pco, orgid, name)
+		if err != nil {
+			diags := append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Unable to find ENV " + name,
+				Detail:   err.Error(),
+			})
+			return diags
+		}
+		envid = id
+	}
+
 	//request env
 	res, httpr, err := pco.envclient.DefaultApi.OrganizationsOrgIdEnvironmentsEnvironmentIdGet(authctx, orgid, envid).Execute()
 	defer httpr.Body.Close()
@@ -132,6 +151,24 @@ func flattenENVData(envitem *env.Env) map[string]interface{} {
 	return nil
 }
 
+/*
+ * Returns the id of the environment with the given name in the given organization
+ */
+func getENVIdByName(ctx context.Context, pco *ProviderConfOutput, orgid string, name string) (string, error) {
+	bg, err := getOrg(getBGAuthCtx(ctx, pco), pco, orgid)
+	if err != nil {
+		return "", err
+	}
+	names := make([]string, 0)
+	for _, e := range bg.GetEnvironments() {
+		if e.GetName() == name {
+			return e.GetId(), nil
+		}
+		names = append(names, e.GetName())
+	}
+	return "", fmt.Errorf("no environment named %q in organization %s, available environments are: %s", name, orgid, strings.Join(names, ", "))
+}
+
 func getENVCoreAttributes() []string {
 	attributes := [...]string{
 		"name", "org_id", "is_production", "type", "client_id",
@@ -0,0 +1,161 @@
+package anypoint
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceENVs() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceENVsRead,
+		Description: `
+		Reads all ` + "`" + `environments` + "`" + ` in your business group matching the filters.
+		`,
+		Schema: map[string]*schema.Schema{
+			"org_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The organization id where the environments are defined.",
+			},
+			"type": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Description:      "If set, only the environments of this type are returned: sandbox, design or production.",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice([]string{"sandbox", "design", "production"}, true)),
+			},
+			"is_production": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "If set, only the environments which production flag matches are returned.",
+			},
+			"name_regex": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Description:      "Only the environments which name matches this regular expression are returned.",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsValidRegExp),
+			},
+			"ids": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The ids of the matching environments.",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"envs": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The matching environments.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The unique id of this environment generated by the anypoint platform.",
+						},
+						"org_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The organization id where the environment is defined.",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The name of the environment",
+						},
+						"is_production": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "True if the environment is a production environment",
+						},
+						"type": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The type of the environment: sandbox, design or production",
+						},
+						"client_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The environment client id",
+						},
+					},
+				},
+			},
+			"len": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The number of matching environments",
+			},
+		},
+	}
+}
+
+func dataSourceENVsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	pco := m.(ProviderConfOutput)
+	orgid := d.Get("org_id").(string)
+	envtype := d.Get("type").(string)
+
+	var nameregex *regexp.Regexp
+	if v := d.Get("name_regex").(string); v != "" {
+		nameregex = regexp.MustCompile(v)
+	}
+	// is_production is a tri-state filter, false must be told apart from unset
+	var isproduction *bool
+	if !d.GetRawConfig().GetAttr("is_production").IsNull() {
+		v := d.Get("is_production").(bool)
+		isproduction = &v
+	}
+
+	bg, err := getOrg(getBGAuthCtx(ctx, &pco), &pco, orgid)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to Get ENVs",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	list := make([]interface{}, 0)
+	ids := make([]string, 0)
+	for _, e := range bg.GetEnvironments() {
+		if envtype != "" && !strings.EqualFold(e.GetType(), envtype) {
+			continue
+		}
+		if isproduction != nil && e.GetIsProduction() != *isproduction {
+			continue
+		}
+		if nameregex != nil && !nameregex.MatchString(e.GetName()) {
+			continue
+		}
+		list = append(list, map[string]interface{}{
+			"id":            e.GetId(),
+			"org_id":        e.GetOrganizationId(),
+			"name":          e.GetName(),
+			"is_production": e.GetIsProduction(),
+			"type":          e.GetType(),
+			"client_id":     e.GetClientId(),
+		})
+		ids = append(ids, e.GetId())
+	}
+
+	if err := d.Set("envs", list); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to set ENVs",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	d.Set("ids", ids)
+	d.Set("len", len(list))
+	d.SetId(orgid)
+
+	return diags
+}
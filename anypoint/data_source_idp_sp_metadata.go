@@ -0,0 +1,303 @@
+package anypoint
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/pem"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const SAML_NAMEID_FORMAT_UNSPECIFIED = "urn:oasis:names:tc:SAML:1.1:nameid-format:unspecified"
+const SAML_BINDING_HTTP_POST = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST"
+
+func dataSourceIDPSPMetadata() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceIDPSPMetadataRead,
+		Description: `
+		Generates the anypoint platform's service provider SAML metadata for a given SAML ` + "`" + `identity provider` + "`" + `.
+		The metadata and its individual fields can be used to register the anypoint platform in the identity provider.
+		`,
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The unique id of this metadata composed by {org_id}/{provider_id}",
+			},
+			"org_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The master organization id where the idp is defined.",
+			},
+			"provider_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The identity provider unique generated id",
+			},
+			"signing_certificate": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "The service provider's certificate (PEM or base64 DER) published in the metadata. It is read from the identity provider's service provider configuration, set it only to override the platform's certificate. The metadata contains no key descriptor if the platform has none and no override is set.",
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The name of the identity provider",
+			},
+			"entity_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The service provider's entity id, corresponds to the audience of the identity provider.",
+			},
+			"acs_url": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The service provider's assertion consumer service url.",
+			},
+			"login_url": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The anypoint platform's login url for the organization's domain, used for service provider initiated SSO.",
+			},
+			"want_assertions_encrypted": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "True if the service provider requires the SAML assertions to be encrypted.",
+			},
+			"metadata_xml": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The service provider's SAML metadata XML document.",
+			},
+		},
+	}
+}
+
+func dataSourceIDPSPMetadataRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	pco := m.(ProviderConfOutput)
+	orgid := d.Get("org_id").(string)
+	idpid := d.Get("provider_id").(string)
+	authctx := getIDPAuthCtx(ctx, &pco)
+
+	//request idp
+	res, httpr, err := pco.idpclient.DefaultApi.OrganizationsOrgIdIdentityProvidersIdpIdGet(authctx, orgid, idpid).Execute()
+	if err != nil {
+		var details string
+		if httpr != nil {
+			b, _ := ioutil.ReadAll(httpr.Body)
+			details = string(b)
+		} else {
+			details = err.Error()
+		}
+		diags := append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to Get IDP " + idpid + " in org " + orgid,
+			Detail:   details,
+		})
+		return diags
+	}
+	defer httpr.Body.Close()
+	idpinstance := flattenIDPData(&res)
+	samllist, ok := idpinstance["saml"].([]interface{})
+	if !ok || len(samllist) == 0 {
+		diags := append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "IDP " + idpid + " in org " + orgid + " is not a SAML identity provider",
+			Detail:   "the service provider metadata is only available for SAML identity providers.",
+		})
+		return diags
+	}
+	samldata := samllist[0].(map[string]interface{})
+
+	//request organization to get its domain
+	orgauthctx := getBGAuthCtx(ctx, &pco)
+	org, httpr, err := pco.orgclient.DefaultApi.OrganizationsOrgIdGet(orgauthctx, orgid).Execute()
+	if err != nil {
+		var details string
+		if httpr != nil {
+			b, _ := ioutil.ReadAll(httpr.Body)
+			details = string(b)
+		} else {
+			details = err.Error()
+		}
+		diags := append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to Get Business Group " + orgid,
+			Detail:   details,
+		})
+		return diags
+	}
+	defer httpr.Body.Close()
+	domain := org.GetDomain()
+	if domain == "" {
+		diags := append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Business Group " + orgid + " has no domain",
+			Detail:   "a domain must be defined on the organization to use external identity providers.",
+		})
+		return diags
+	}
+
+	cert := d.Get("signing_certificate").(string)
+	if cert == "" {
+		cert, err = getIDPSPCertificate(ctx, &pco, orgid, idpid)
+		if err != nil {
+			diags := append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Unable to get the service provider certificate of IDP " + idpid + " in org " + orgid,
+				Detail:   err.Error(),
+			})
+			return diags
+		}
+	}
+	cert, err = normalizeSPCertificate(cert)
+	if err != nil {
+		diags := append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Invalid signing_certificate",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	baseurl := anypointBaseUrls[pco.server_index]
+	entityid := samldata["audience"].(string)
+	acsurl := fmt.Sprintf("%s/accounts/login/%s/providers/%s/receive-id", baseurl, domain, idpid)
+	encrypted := samldata["require_encrypted_saml_assertions"].(bool)
+	metadata, err := genSPMetadataXML(entityid, acsurl, cert, encrypted)
+	if err != nil {
+		diags := append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to generate service provider metadata for IDP " + idpid,
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	values := map[string]interface{}{
+		"name":                      idpinstance["name"],
+		"entity_id":                 entityid,
+		"acs_url":                   acsurl,
+		"login_url":                 fmt.Sprintf("%s/accounts/login/%s", baseurl, domain),
+		"want_assertions_encrypted": encrypted,
+		"signing_certificate":       cert,
+		"metadata_xml":              metadata,
+	}
+	for attr, val := range values {
+		if err := d.Set(attr, val); err != nil {
+			diags := append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Unable to set service provider metadata attribute " + attr,
+				Detail:   err.Error(),
+			})
+			return diags
+		}
+	}
+
+	d.SetId(ComposeResourceId([]string{orgid, idpid}))
+
+	return diags
+}
+
+// the service provider part of the identity provider's configuration, not exposed by the idp client
+type idpServiceProviderConfig struct {
+	ServiceProvider struct {
+		Certificate string `json:"certificate"`
+	} `json:"service_provider"`
+}
+
+// reads the service provider certificate from the identity provider's configuration, empty if the platform has none
+func getIDPSPCertificate(ctx context.Context, pco *ProviderConfOutput, orgid, idpid string) (string, error) {
+	var config idpServiceProviderConfig
+	path := fmt.Sprintf("/accounts/api/organizations/%s/identityProviders/%s", orgid, idpid)
+	if _, err := doAnypointRequest(ctx, pco, http.MethodGet, path, nil, &config); err != nil {
+		return "", err
+	}
+	return config.ServiceProvider.Certificate, nil
+}
+
+// service provider metadata elements
+type samlSPEntityDescriptor struct {
+	XMLName         xml.Name            `xml:"md:EntityDescriptor"`
+	XmlnsMd         string              `xml:"xmlns:md,attr"`
+	XmlnsDs         string              `xml:"xmlns:ds,attr,omitempty"`
+	EntityID        string              `xml:"entityID,attr"`
+	SPSSODescriptor samlSPSSODescriptor `xml:"md:SPSSODescriptor"`
+}
+
+type samlSPSSODescriptor struct {
+	AuthnRequestsSigned        bool                    `xml:"AuthnRequestsSigned,attr"`
+	WantAssertionsSigned       bool                    `xml:"WantAssertionsSigned,attr"`
+	ProtocolSupportEnumeration string                  `xml:"protocolSupportEnumeration,attr"`
+	KeyDescriptors             []samlSPKeyDescriptor   `xml:"md:KeyDescriptor"`
+	NameIDFormats              []string                `xml:"md:NameIDFormat"`
+	AssertionConsumerServices  []samlSPIndexedEndpoint `xml:"md:AssertionConsumerService"`
+}
+
+type samlSPKeyDescriptor struct {
+	Use             string `xml:"use,attr"`
+	X509Certificate string `xml:"ds:KeyInfo>ds:X509Data>ds:X509Certificate"`
+}
+
+type samlSPIndexedEndpoint struct {
+	Binding   string `xml:"Binding,attr"`
+	Location  string `xml:"Location,attr"`
+	Index     int    `xml:"index,attr"`
+	IsDefault bool   `xml:"isDefault,attr"`
+}
+
+// generates the service provider metadata document, the key descriptors are only added if a certificate is given
+func genSPMetadataXML(entityid, acsurl, cert string, encrypted bool) (string, error) {
+	descriptor := samlSPEntityDescriptor{
+		XmlnsMd:  "urn:oasis:names:tc:SAML:2.0:metadata",
+		EntityID: entityid,
+		SPSSODescriptor: samlSPSSODescriptor{
+			AuthnRequestsSigned:        false,
+			WantAssertionsSigned:       true,
+			ProtocolSupportEnumeration: "urn:oasis:names:tc:SAML:2.0:protocol",
+			NameIDFormats:              []string{SAML_NAMEID_FORMAT_UNSPECIFIED},
+			AssertionConsumerServices: []samlSPIndexedEndpoint{
+				{Binding: SAML_BINDING_HTTP_POST, Location: acsurl, Index: 0, IsDefault: true},
+			},
+		},
+	}
+	if cert != "" {
+		descriptor.XmlnsDs = "http://www.w3.org/2000/09/xmldsig#"
+		descriptor.SPSSODescriptor.KeyDescriptors = append(descriptor.SPSSODescriptor.KeyDescriptors, samlSPKeyDescriptor{Use: "signing", X509Certificate: cert})
+		if encrypted {
+			descriptor.SPSSODescriptor.KeyDescriptors = append(descriptor.SPSSODescriptor.KeyDescriptors, samlSPKeyDescriptor{Use: "encryption", X509Certificate: cert})
+		}
+	}
+	b, err := xml.MarshalIndent(descriptor, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return xml.Header + string(b) + "\n", nil
+}
+
+// returns the base64 DER content of the given PEM or base64 DER certificate
+func normalizeSPCertificate(cert string) (string, error) {
+	cert = strings.TrimSpace(cert)
+	if cert == "" {
+		return "", nil
+	}
+	if block, _ := pem.Decode([]byte(cert)); block != nil {
+		if block.Type != "CERTIFICATE" {
+			return "", fmt.Errorf("expected a CERTIFICATE PEM block, got %s", block.Type)
+		}
+		return base64.StdEncoding.EncodeToString(block.Bytes), nil
+	}
+	cert = strings.Join(strings.Fields(cert), "")
+	if _, err := base64.StdEncoding.DecodeString(cert); err != nil {
+		return "", fmt.Errorf("the certificate is neither PEM nor base64 encoded: %s", err)
+	}
+	return cert, nil
+}
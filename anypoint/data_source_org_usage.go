@@ -0,0 +1,315 @@
+package anypoint
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	org "github.com/mulesoft-anypoint/anypoint-client-go/org"
+)
+
+// cloudhub's usage report of the organization selected through the X-ANYPNT-ORG-ID header
+type orgCloudHubUsage struct {
+	Usage struct {
+		ProductionVCores float64 `json:"productionVCores"`
+		SandboxVCores    float64 `json:"sandboxVCores"`
+		DesignVCores     float64 `json:"designVCores"`
+		StaticIps        float64 `json:"staticIps"`
+		Vpcs             float64 `json:"vpcs"`
+		Vpns             float64 `json:"vpns"`
+		LoadBalancers    float64 `json:"loadBalancers"`
+	} `json:"usage"`
+}
+
+// the capacity entitlements reported by the usage data source, the keys match BG_CAPACITY_ENTITLEMENTS
+var ORG_USAGE_METRICS = []struct {
+	Attr           string
+	EntitlementKey string
+}{
+	{"vcores_production", "vcoresproduction"},
+	{"vcores_sandbox", "vcoressandbox"},
+	{"vcores_design", "vcoresdesign"},
+	{"static_ips", "staticips"},
+	{"vpcs", "vpcs"},
+	{"vpns", "vpns"},
+	{"load_balancers", "loadbalancer"},
+}
+
+func dataSourceOrgUsage() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceOrgUsageRead,
+		Description: `
+		Reports the entitlements usage of a business group and all its descendants.
+		For each business group the assigned capacity is compared to the capacity consumed by its own deployments and reassigned to its children,
+		the consumption is also rolled up at every level of the hierarchy.
+		`,
+		Schema: map[string]*schema.Schema{
+			"org_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The business group id to start the report from.",
+			},
+			"max_depth": {
+				Type:             schema.TypeInt,
+				Optional:         true,
+				Default:          0,
+				Description:      "The maximum depth of descendants to report, 0 means the whole hierarchy.",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(0)),
+			},
+			"business_groups": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The usage of each business group, the root business group comes first followed by its descendants in depth-first order.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The business group id.",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The business group name.",
+						},
+						"parent_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The id of the immediate parent business group, empty for the root of the report.",
+						},
+						"depth": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The depth of the business group relative to the root of the report.",
+						},
+						"sub_organization_ids": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "The ids of the immediate children of the business group.",
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"vcores_production": orgUsageMetricSchema("The production vCores usage."),
+						"vcores_sandbox":    orgUsageMetricSchema("The sandbox vCores usage."),
+						"vcores_design":     orgUsageMetricSchema("The design vCores usage."),
+						"static_ips":        orgUsageMetricSchema("The static IPs usage."),
+						"vpcs":              orgUsageMetricSchema("The VPCs usage."),
+						"vpns":              orgUsageMetricSchema("The VPNs usage."),
+						"load_balancers":    orgUsageMetricSchema("The dedicated load balancers usage."),
+						"mq_quota": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "The Anypoint MQ quota, the MQ consumption is not part of this report.",
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"messages": {
+										Type:        schema.TypeInt,
+										Computed:    true,
+										Description: "The MQ messages quota (base and add-on) of the business group.",
+									},
+									"requests": {
+										Type:        schema.TypeInt,
+										Computed:    true,
+										Description: "The MQ API requests quota (base and add-on) of the business group.",
+									},
+									"total_messages": {
+										Type:        schema.TypeInt,
+										Computed:    true,
+										Description: "The MQ messages quota of the business group and all its reported descendants.",
+									},
+									"total_requests": {
+										Type:        schema.TypeInt,
+										Computed:    true,
+										Description: "The MQ API requests quota of the business group and all its reported descendants.",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"len": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The number of reported business groups",
+			},
+		},
+	}
+}
+
+func orgUsageMetricSchema(description string) *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Computed:    true,
+		Description: description,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"assigned": {
+					Type:        schema.TypeFloat,
+					Computed:    true,
+					Description: "The capacity assigned to the business group.",
+				},
+				"reassigned": {
+					Type:        schema.TypeFloat,
+					Computed:    true,
+					Description: "The capacity reassigned by the business group to its children.",
+				},
+				"consumed": {
+					Type:        schema.TypeFloat,
+					Computed:    true,
+					Description: "The capacity consumed by the business group's own deployments.",
+				},
+				"available": {
+					Type:        schema.TypeFloat,
+					Computed:    true,
+					Description: "The remaining capacity: assigned - reassigned - consumed.",
+				},
+				"total_consumed": {
+					Type:        schema.TypeFloat,
+					Computed:    true,
+					Description: "The capacity consumed by the business group and all its reported descendants.",
+				},
+			},
+		},
+	}
+}
+
+func dataSourceOrgUsageRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	pco := m.(ProviderConfOutput)
+	orgid := d.Get("org_id").(string)
+	maxdepth := d.Get("max_depth").(int)
+
+	tree, err := getBGTree(ctx, &pco, orgid)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to get Business Group " + orgid + " hierarchy",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	list := make([]interface{}, 0)
+	if _, err := collectOrgUsage(ctx, &pco, tree, orgid, "", 0, maxdepth, &list); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to get usage of Business Group " + orgid,
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	if err := d.Set("business_groups", list); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to set usage of Business Group " + orgid,
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	d.Set("len", len(list))
+	d.SetId(orgid)
+
+	return diags
+}
+
+/*
+Walks the business group hierarchy depth-first and appends the usage of each business group to the list.
+Returns the usage item of the given business group which totals include its descendants.
+*/
+func collectOrgUsage(ctx context.Context, pco *ProviderConfOutput, tree *bgTree, orgid string, parentid string, depth int, maxdepth int, list *[]interface{}) (map[string]interface{}, error) {
+	usage, err := getOrgCloudHubUsage(ctx, pco, orgid)
+	if err != nil {
+		return nil, err
+	}
+	item := flattenOrgUsage(tree.orgs[orgid], usage)
+	item["parent_id"] = parentid
+	item["depth"] = depth
+	*list = append(*list, item)
+
+	children := make([]string, 0)
+	if maxdepth == 0 || depth < maxdepth {
+		for _, subid := range tree.children[orgid] {
+			children = append(children, subid)
+			child, err := collectOrgUsage(ctx, pco, tree, subid, orgid, depth+1, maxdepth, list)
+			if err != nil {
+				return nil, err
+			}
+			rollUpOrgUsage(item, child)
+		}
+	}
+	item["sub_organization_ids"] = children
+
+	return item, nil
+}
+
+func getOrgCloudHubUsage(ctx context.Context, pco *ProviderConfOutput, orgid string) (*orgCloudHubUsage, error) {
+	var usage orgCloudHubUsage
+	headers := map[string]string{"X-ANYPNT-ORG-ID": orgid}
+	if _, err := doAnypointRequestWithHeaders(ctx, pco, http.MethodGet, "/cloudhub/api/organization", headers, nil, &usage); err != nil {
+		return nil, err
+	}
+	return &usage, nil
+}
+
+func flattenOrgUsage(bg *org.MasterBGDetail, usage *orgCloudHubUsage) map[string]interface{} {
+	data := flattenBGData(bg)
+	consumed := map[string]float64{
+		"vcoresproduction": usage.Usage.ProductionVCores,
+		"vcoressandbox":    usage.Usage.SandboxVCores,
+		"vcoresdesign":     usage.Usage.DesignVCores,
+		"staticips":        usage.Usage.StaticIps,
+		"vpcs":             usage.Usage.Vpcs,
+		"vpns":             usage.Usage.Vpns,
+		"loadbalancer":     usage.Usage.LoadBalancers,
+	}
+	item := map[string]interface{}{
+		"id":   bg.GetId(),
+		"name": bg.GetName(),
+	}
+	for _, metric := range ORG_USAGE_METRICS {
+		assigned := entitlementValue2Float64(data["entitlements_"+metric.EntitlementKey+"_assigned"])
+		reassigned := entitlementValue2Float64(data["entitlements_"+metric.EntitlementKey+"_reassigned"])
+		c := consumed[metric.EntitlementKey]
+		item[metric.Attr] = []interface{}{
+			map[string]interface{}{
+				"assigned":       assigned,
+				"reassigned":     reassigned,
+				"consumed":       c,
+				"available":      assigned - reassigned - c,
+				"total_consumed": c,
+			},
+		}
+	}
+	entitlements := bg.GetEntitlements()
+	mqmessages := entitlements.GetMqMessages()
+	mqrequests := entitlements.GetMqRequests()
+	messages := int(mqmessages.GetBase()) + int(mqmessages.GetAddOn())
+	requests := int(mqrequests.GetBase()) + int(mqrequests.GetAddOn())
+	item["mq_quota"] = []interface{}{
+		map[string]interface{}{
+			"messages":       messages,
+			"requests":       requests,
+			"total_messages": messages,
+			"total_requests": requests,
+		},
+	}
+	return item
+}
+
+// adds the totals of the child's usage to the parent's
+func rollUpOrgUsage(parent map[string]interface{}, child map[string]interface{}) {
+	for _, metric := range ORG_USAGE_METRICS {
+		p := parent[metric.Attr].([]interface{})[0].(map[string]interface{})
+		c := child[metric.Attr].([]interface{})[0].(map[string]interface{})
+		p["total_consumed"] = p["total_consumed"].(float64) + c["total_consumed"].(float64)
+	}
+	p := parent["mq_quota"].([]interface{})[0].(map[string]interface{})
+	c := child["mq_quota"].([]interface{})[0].(map[string]interface{})
+	p["total_messages"] = p["total_messages"].(int) + c["total_messages"].(int)
+	p["total_requests"] = p["total_requests"].(int) + c["total_requests"].(int)
+}
@@ -57,7 +57,7 @@ func dataSourceRoles() *schema.Resource {
 							Type:        schema.TypeInt,
 							Optional:    true,
 							Default:     200,
-							Description: "Pagination parameter for how many results to return",
+							Description: "Pagination parameter for how many results to return per request, all pages are loaded automatically",
 						},
 						"ascending": {
 							Type:        schema.TypeBool,
@@ -142,26 +142,37 @@ func dataSourceRolesRead(ctx context.Context, d *schema.ResourceData, m interfac
 		return diags
 	}
 
-	//request roles
-	res, httpr, err := req.Execute()
-	defer httpr.Body.Close()
-	if err != nil {
-		var details string
-		if httpr != nil {
-			b, _ := ioutil.ReadAll(httpr.Body)
-			details = string(b)
-		} else {
-			details = err.Error()
+	//request all pages of roles
+	offset, limit := getPaginationParams(searchOpts, 200)
+	data := make([]role.Role, 0)
+	total := 0
+	for {
+		res, httpr, err := req.Offset(int32(offset)).Limit(int32(limit)).Execute()
+		if err != nil {
+			var details string
+			if httpr != nil {
+				b, _ := ioutil.ReadAll(httpr.Body)
+				details = string(b)
+			} else {
+				details = err.Error()
+			}
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Unable to Get Roles",
+				Detail:   details,
+			})
+			return diags
+		}
+		httpr.Body.Close()
+		page := res.GetData()
+		data = append(data, page...)
+		total = int(res.GetTotal())
+		offset += len(page)
+		if len(page) < limit || offset >= total {
+			break
 		}
-		diags = append(diags, diag.Diagnostic{
-			Severity: diag.Error,
-			Summary:  "Unable to Get Roles",
-			Detail:   details,
-		})
-		return diags
 	}
 	//process data
-	data := res.GetData()
 	roles := flattenRolesData(&data)
 	//save in data source schema
 	if err := d.Set("roles", roles); err != nil {
@@ -182,7 +193,7 @@ func dataSourceRolesRead(ctx context.Context, d *schema.ResourceData, m interfac
 		return diags
 	}
 
-	if err := d.Set("total", res.GetTotal()); err != nil {
+	if err := d.Set("total", total); err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "Unable to set total number roles",
@@ -224,14 +235,6 @@ func parseRoleSearchOpts(req role.DefaultApiApiRolesGetRequest, params *schema.S
 			req = req.Search(v.(string))
 			continue
 		}
-		if k == "offset" {
-			req = req.Offset(int32(v.(int)))
-			continue
-		}
-		if k == "limit" {
-			req = req.Limit(int32(v.(int)))
-			continue
-		}
 		if k == "ascending" {
 			req = req.Ascending(v.(bool))
 			continue
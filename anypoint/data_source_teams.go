@@ -71,7 +71,7 @@ func dataSourceTeams() *schema.Resource {
 							Type:        schema.TypeInt,
 							Optional:    true,
 							Default:     200,
-							Description: "Maximum records to retrieve per request.",
+							Description: "Maximum records to retrieve per request, all pages are loaded automatically.",
 						},
 						"sort": {
 							Type:        schema.TypeString,
@@ -168,26 +168,37 @@ func dataSourceTeamsRead(ctx context.Context, d *schema.ResourceData, m interfac
 		return diags
 	}
 
-	//request roles
-	res, httpr, err := req.Execute()
-	if err != nil {
-		var details string
-		if httpr != nil {
-			b, _ := ioutil.ReadAll(httpr.Body)
-			details = string(b)
-		} else {
-			details = err.Error()
+	//request all pages of teams
+	offset, limit := getPaginationParams(searchOpts, 200)
+	data := make([]team.Team, 0)
+	total := 0
+	for {
+		res, httpr, err := req.Offset(int32(offset)).Limit(int32(limit)).Execute()
+		if err != nil {
+			var details string
+			if httpr != nil {
+				b, _ := ioutil.ReadAll(httpr.Body)
+				details = string(b)
+			} else {
+				details = err.Error()
+			}
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Unable to get teams",
+				Detail:   details,
+			})
+			return diags
+		}
+		httpr.Body.Close()
+		page := res.GetData()
+		data = append(data, page...)
+		total = int(res.GetTotal())
+		offset += len(page)
+		if len(page) < limit || offset >= total {
+			break
 		}
-		diags = append(diags, diag.Diagnostic{
-			Severity: diag.Error,
-			Summary:  "Unable to get teams",
-			Detail:   details,
-		})
-		return diags
 	}
-	defer httpr.Body.Close()
 	//process data
-	data := res.GetData()
 	teams := flattenTeamsData(&data)
 	//save in data source schema
 	if err := d.Set("teams", teams); err != nil {
@@ -207,7 +218,7 @@ func dataSourceTeamsRead(ctx context.Context, d *schema.ResourceData, m interfac
 		return diags
 	}
 
-	if err := d.Set("total", res.GetTotal()); err != nil {
+	if err := d.Set("total", total); err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "Unable to set total number of teams",
@@ -230,12 +241,12 @@ func parseTeamSearchOpts(req team.DefaultApiApiOrganizationsOrgIdTeamsGetRequest
 	opts := params.List()[0]
 
 	for k, v := range opts.(map[string]interface{}) {
-		if k == "ancestor_team_id" {
-			req = req.AncestorTeamId(v.([]string))
+		if k == "ancestor_team_id" && len(v.([]interface{})) > 0 {
+			req = req.AncestorTeamId(ListInterface2ListStrings(v.([]interface{})))
 			continue
 		}
-		if k == "parent_team_id" {
-			req = req.ParentTeamId(v.([]string))
+		if k == "parent_team_id" && len(v.([]interface{})) > 0 {
+			req = req.ParentTeamId(ListInterface2ListStrings(v.([]interface{})))
 			continue
 		}
 		if k == "team_id" {
@@ -250,14 +261,6 @@ func parseTeamSearchOpts(req team.DefaultApiApiOrganizationsOrgIdTeamsGetRequest
 			req = req.Search(v.(string))
 			continue
 		}
-		if k == "offset" {
-			req = req.Offset(int32(v.(int)))
-			continue
-		}
-		if k == "limit" {
-			req = req.Limit(int32(v.(int)))
-			continue
-		}
 		if k == "sort" {
 			req = req.Sort(v.(string))
 			continue
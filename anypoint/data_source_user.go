@@ -209,6 +209,15 @@ func flattenUserData(usr *user.User) map[string]interface{} {
 	if val, ok := usr.GetOrganizationIdOk(); ok {
 		res["organization_id"] = *val
 	}
+	if val, ok := usr.GetFirstNameOk(); ok {
+		res["first_name"] = *val
+	}
+	if val, ok := usr.GetLastNameOk(); ok {
+		res["last_name"] = *val
+	}
+	if val, ok := usr.GetEmailOk(); ok {
+		res["email"] = *val
+	}
 	if val, ok := usr.GetPhoneNumberOk(); ok {
 		res["phone_number"] = *val
 	}
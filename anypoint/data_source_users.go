@@ -2,12 +2,16 @@ package anypoint
 
 import (
 	"context"
-	"io/ioutil"
+	"fmt"
+	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/mulesoft-anypoint/anypoint-client-go/user"
 )
 
@@ -16,6 +20,8 @@ func dataSourceUsers() *schema.Resource {
 		ReadContext: dataSourceUsersRead,
 		Description: `
 		Reads the ` + "`" + `users` + "`" + ` available in the business group.
+		The type and the first of username, email or name set in the search parameters are sent to the platform, which narrows the loaded users.
+		All the other filters are applied by the provider on the loaded users.
 		`,
 		Schema: map[string]*schema.Schema{
 			"org_id": {
@@ -39,7 +45,7 @@ func dataSourceUsers() *schema.Resource {
 							Type:        schema.TypeInt,
 							Optional:    true,
 							Default:     200,
-							Description: "Maximum records to retrieve per request. default 25, min 0, max 500",
+							Description: "Maximum records to retrieve per request, all pages are loaded automatically. default 200, min 1, max 500",
 						},
 						"type": {
 							Type:        schema.TypeString,
@@ -47,6 +53,45 @@ func dataSourceUsers() *schema.Resource {
 							Default:     "all",
 							Description: "specify the type of the user you want to retrive [all, host, proxy]",
 						},
+						"email": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Returns only the users having the given email (case insensitive). Sent to the platform as search when username is not set.",
+						},
+						"username": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Returns only the user having the given username. Sent to the platform as search.",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Returns only the users whose first name, last name or full name contains the given string (case insensitive). Sent to the platform as search when neither username nor email are set.",
+						},
+						"idprovider_id": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Returns only the users of the given identity provider. Applied by the provider.",
+						},
+						"enabled": {
+							Type:             schema.TypeString,
+							Optional:         true,
+							Default:          "all",
+							Description:      "Filters the users by their enabled state. Applied by the provider. Enum values: all, true, false",
+							ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice([]string{"all", "true", "false"}, false)),
+						},
+						"last_login_after": {
+							Type:             schema.TypeString,
+							Optional:         true,
+							Description:      "Returns only the users who logged in after the given time (RFC3339 format). Applied by the provider.",
+							ValidateDiagFunc: validation.ToDiagFunc(validation.IsRFC3339Time),
+						},
+						"last_login_before": {
+							Type:             schema.TypeString,
+							Optional:         true,
+							Description:      "Returns only the users who logged in before the given time (RFC3339 format). Applied by the provider.",
+							ValidateDiagFunc: validation.ToDiagFunc(validation.IsRFC3339Time),
+						},
 					},
 				},
 			},
@@ -161,12 +206,12 @@ func dataSourceUsers() *schema.Resource {
 			},
 			"len": {
 				Type:        schema.TypeInt,
-				Description: "The number of loaded results (for pagination purposes).",
+				Description: "The number of loaded results matching the filters.",
 				Computed:    true,
 			},
 			"total": {
 				Type:        schema.TypeInt,
-				Description: "The total number of available results",
+				Description: "The total number of available results before filtering",
 				Computed:    true,
 			},
 		},
@@ -178,36 +223,39 @@ func dataSourceUsersRead(ctx context.Context, d *schema.ResourceData, m interfac
 	pco := m.(ProviderConfOutput)
 	searchOpts := d.Get("params").(*schema.Set)
 	orgid := d.Get("org_id").(string)
-	authctx := getUserAuthCtx(ctx, &pco)
 
-	req := pco.userclient.DefaultApi.OrganizationsOrgIdUsersGet(authctx, orgid)
-	req, errDiags := parseUsersSearchOpts(req, searchOpts)
-	if errDiags.HasError() {
-		diags = append(diags, errDiags...)
-		return diags
-	}
+	query := newUsersSearchQuery(searchOpts)
 
-	//request roles
-	res, httpr, err := req.Execute()
-	if err != nil {
-		var details string
-		if httpr != nil {
-			b, _ := ioutil.ReadAll(httpr.Body)
-			details = string(b)
-		} else {
-			details = err.Error()
+	//request all pages of users
+	offset, limit := getPaginationParams(searchOpts, 200)
+	data := make([]user.User, 0)
+	total := 0
+	for {
+		//the search parameter is not exposed by the generated client
+		var res struct {
+			Data  []user.User `json:"data"`
+			Total int         `json:"total"`
+		}
+		query.Set("offset", strconv.Itoa(offset))
+		query.Set("limit", strconv.Itoa(limit))
+		if _, err := doAnypointRequest(ctx, &pco, http.MethodGet, "/accounts/api/organizations/"+orgid+"/users?"+query.Encode(), nil, &res); err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Unable to get users",
+				Detail:   err.Error(),
+			})
+			return diags
+		}
+		page := res.Data
+		data = append(data, page...)
+		total = res.Total
+		offset += len(page)
+		if len(page) < limit || offset >= total {
+			break
 		}
-		diags = append(diags, diag.Diagnostic{
-			Severity: diag.Error,
-			Summary:  "Unable to get users",
-			Detail:   details,
-		})
-		return diags
 	}
-	defer httpr.Body.Close()
 	//process data
-	data := res.GetData()
-	users := flattenUsersData(&data)
+	users := filterUsersData(flattenUsersData(&data), searchOpts)
 	//save in data source schema
 	if err := d.Set("users", users); err != nil {
 		diags = append(diags, diag.Diagnostic{
@@ -226,7 +274,7 @@ func dataSourceUsersRead(ctx context.Context, d *schema.ResourceData, m interfac
 		return diags
 	}
 
-	if err := d.Set("total", res.GetTotal()); err != nil {
+	if err := d.Set("total", total); err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  "Unable to set total number of users",
@@ -241,33 +289,82 @@ func dataSourceUsersRead(ctx context.Context, d *schema.ResourceData, m interfac
 }
 
 /*
-Parses the users search options in order to check if the required search parameters are set correctly.
-Appends the parameters to the given request
+Builds the query of the users search from the search options.
+The type and a single search term are handled by the platform, the other filters are applied by filterUsersData.
+Pagination parameters are handled while loading the pages.
 */
-func parseUsersSearchOpts(req user.DefaultApiApiOrganizationsOrgIdUsersGetRequest, params *schema.Set) (user.DefaultApiApiOrganizationsOrgIdUsersGetRequest, diag.Diagnostics) {
-	var diags diag.Diagnostics
+func newUsersSearchQuery(params *schema.Set) url.Values {
+	query := url.Values{}
 	if params.Len() == 0 {
-		return req, diags
+		return query
+	}
+	opts := params.List()[0].(map[string]interface{})
+	if t := opts["type"].(string); t != "" {
+		query.Set("type", t)
+	}
+	//the platform searches the term in the username, email and names, the most selective one is sent
+	for _, k := range []string{"username", "email", "name"} {
+		if v := opts[k].(string); v != "" {
+			query.Set("search", v)
+			break
+		}
 	}
+	return query
+}
 
-	opts := params.List()[0]
+/*
+Filters the given flattened users using the filters of the search options.
+The search sent to the platform is a partial match, all the filters are therefore applied.
+*/
+func filterUsersData(users []interface{}, params *schema.Set) []interface{} {
+	if params.Len() == 0 {
+		return users
+	}
+	opts := params.List()[0].(map[string]interface{})
+	email := opts["email"].(string)
+	username := opts["username"].(string)
+	name := strings.ToLower(opts["name"].(string))
+	idproviderid := opts["idprovider_id"].(string)
+	enabled := opts["enabled"].(string)
+	after, _ := time.Parse(time.RFC3339, opts["last_login_after"].(string))
+	before, _ := time.Parse(time.RFC3339, opts["last_login_before"].(string))
 
-	for k, v := range opts.(map[string]interface{}) {
-		if k == "offset" {
-			req = req.Offset(int32(v.(int)))
-			continue
+	return FilterMapList(users, func(usr map[string]interface{}) bool {
+		if email != "" && !strings.EqualFold(fmt.Sprint(usr["email"]), email) {
+			return false
 		}
-		if k == "limit" {
-			req = req.Limit(int32(v.(int)))
-			continue
+		if username != "" && fmt.Sprint(usr["username"]) != username {
+			return false
 		}
-		if k == "type" {
-			req = req.Type_(v.(string))
-			continue
+		if name != "" {
+			firstname, _ := usr["first_name"].(string)
+			lastname, _ := usr["last_name"].(string)
+			fullname := strings.ToLower(firstname + " " + lastname)
+			if !strings.Contains(fullname, name) {
+				return false
+			}
 		}
-	}
-
-	return req, diags
+		if idproviderid != "" && fmt.Sprint(usr["idprovider_id"]) != idproviderid {
+			return false
+		}
+		if enabled != "all" && enabled != "" && fmt.Sprint(usr["enabled"]) != enabled {
+			return false
+		}
+		if !after.IsZero() || !before.IsZero() {
+			lastlogin, err := time.Parse(time.RFC3339, fmt.Sprint(usr["last_login"]))
+			if err != nil {
+				// users that never logged in are out of any login window
+				return false
+			}
+			if !after.IsZero() && !lastlogin.After(after) {
+				return false
+			}
+			if !before.IsZero() && !lastlogin.Before(before) {
+				return false
+			}
+		}
+		return true
+	})
 }
 
 /*
@@ -0,0 +1,770 @@
+package anypoint
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// the resource types generated by the export, in the order they are crawled and written
+var EXPORT_RESOURCE_TYPES = []string{
+	"anypoint_bg", "anypoint_env", "anypoint_team", "anypoint_team_roles",
+	"anypoint_vpc", "anypoint_dlb", "anypoint_amq", "anypoint_ame",
+}
+
+// the variable holding the id of the exported business group in the generated configuration
+const EXPORT_ROOT_ORG_VARIABLE = "root_org_id"
+
+// the provider's source address in the generated configuration
+const EXPORT_PROVIDER_SOURCE = "anypoint.mulesoft.com/automation/anypoint"
+
+// the options of the export of an existing business group to terraform configuration
+type ExportOptions struct {
+	// the business group to export, defaults to the credentials profile's org_id
+	OrgId string
+	// the directory where the configuration files are written
+	OutputDir string
+	// the provider's credentials profile and control plane, the provider's environment variables apply as well
+	Profile         string
+	CredentialsFile string
+	Cplane          string
+	// the resource types to export, all the supported types when empty
+	ResourceTypes []string
+}
+
+// the outcome of an export
+type ExportResult struct {
+	// the number of exported resources per resource type
+	Resources map[string]int
+	// the resources and listings which could not be read and are left out of the configuration
+	Warnings []string
+}
+
+// a crawled resource as read by the provider
+type exportedResource struct {
+	resourceType string
+	name         string
+	importId     string
+	data         *schema.ResourceData
+	// the references which only apply to this resource, they take precedence over the exporter's
+	refs map[string]string
+}
+
+type orgExporter struct {
+	ctx       context.Context
+	pco       ProviderConfOutput
+	provider  *schema.Provider
+	types     []string
+	resources []*exportedResource
+	// the names given so far per resource type
+	names map[string]map[string]bool
+	// the references replacing the known ids in the generated configuration
+	refs   map[string]string
+	result *ExportResult
+}
+
+var exportNameInvalidChars = regexp.MustCompile(`[^a-z0-9_]+`)
+
+/*
+Crawls the given business group and its descendants using the provider's resources
+and writes their terraform configuration along with the import blocks of every resource.
+The ids of the exported resources are replaced by references wherever they are used.
+*/
+func ExportOrg(ctx context.Context, opts ExportOptions) (*ExportResult, error) {
+	for _, t := range opts.ResourceTypes {
+		if !StringInSlice(EXPORT_RESOURCE_TYPES, t, false) {
+			return nil, fmt.Errorf("unsupported resource type %s, the supported types are %s", t, strings.Join(EXPORT_RESOURCE_TYPES, ", "))
+		}
+	}
+
+	p := Provider()
+	config := make(map[string]interface{})
+	for k, v := range map[string]string{"profile": opts.Profile, "credentials_file": opts.CredentialsFile, "cplane": opts.Cplane} {
+		if v != "" {
+			config[k] = v
+		}
+	}
+	if diags := p.Configure(ctx, terraform.NewResourceConfigRaw(config)); diags.HasError() {
+		return nil, exportDiagsError(diags)
+	}
+
+	e := &orgExporter{
+		ctx:      ctx,
+		pco:      p.Meta().(ProviderConfOutput),
+		provider: p,
+		types:    opts.ResourceTypes,
+		names:    make(map[string]map[string]bool),
+		refs:     make(map[string]string),
+		result: &ExportResult{
+			Resources: make(map[string]int),
+			Warnings:  make([]string, 0),
+		},
+	}
+	orgid := opts.OrgId
+	if orgid == "" {
+		profile, err := loadCredentialsProfile(opts.CredentialsFile, opts.Profile)
+		if err != nil {
+			return nil, err
+		}
+		orgid = profile["org_id"]
+	}
+	if orgid == "" {
+		return nil, fmt.Errorf("the business group to export is missing, set the org id or the profile's org_id")
+	}
+
+	if err := e.crawl(orgid); err != nil {
+		return e.result, err
+	}
+	if err := e.write(opts.OutputDir, orgid); err != nil {
+		return e.result, err
+	}
+	return e.result, nil
+}
+
+func (e *orgExporter) crawl(orgid string) error {
+	tree, err := getBGTree(e.ctx, &e.pco, orgid)
+	if err != nil {
+		return err
+	}
+	root := tree.orgs[orgid]
+	// the exported business group is not managed by the configuration, it is passed as a variable
+	e.refs[orgid] = "var." + EXPORT_ROOT_ORG_VARIABLE
+
+	bgs := make([]interface{}, 0)
+	collectBGs(tree, orgid, "", "", 0, &bgsFilter{}, &bgs)
+
+	envs := make([]map[string]string, 0)
+	for _, item := range bgs {
+		bg := item.(map[string]interface{})
+		bgid := bg["id"].(string)
+		// the names are built from the business group's path below the exported business group
+		bgname := strings.TrimPrefix(strings.TrimPrefix(bg["path"].(string), root.GetName()), BG_PATH_SEPARATOR)
+		if bg["depth"].(int) > 0 {
+			if r := e.export("anypoint_bg", bgname, bgid); r != nil {
+				e.refs[bgid] = r.address() + ".id"
+			}
+		}
+		for _, envitem := range bg["environments"].([]interface{}) {
+			env := envitem.(map[string]interface{})
+			envid := env["id"].(string)
+			envname := joinExportName(bgname, env["name"].(string))
+			if r := e.export("anypoint_env", envname, ComposeResourceId([]string{bgid, envid})); r != nil {
+				e.refs[envid] = r.address() + ".id"
+			}
+			envs = append(envs, map[string]string{"org_id": bgid, "id": envid, "name": envname})
+		}
+	}
+
+	if e.selected("anypoint_team") || e.selected("anypoint_team_roles") {
+		if err := e.crawlTeams(getBGMasterId(root)); err != nil {
+			return err
+		}
+	}
+	if e.selected("anypoint_vpc") || e.selected("anypoint_dlb") {
+		for _, item := range bgs {
+			if err := e.crawlVPCs(item.(map[string]interface{})["id"].(string)); err != nil {
+				return err
+			}
+		}
+	}
+	if e.selected("anypoint_amq") || e.selected("anypoint_ame") {
+		for _, env := range envs {
+			if err := e.crawlDestinations(env["org_id"], env["id"], env["name"]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// exports the teams of the master organization and their roles, the root teams are created with the organization
+func (e *orgExporter) crawlTeams(masterid string) error {
+	authctx := getTeamAuthCtx(e.ctx, &e.pco)
+	teams := make([]map[string]interface{}, 0)
+	offset, limit := 0, 200
+	for {
+		res, httpr, err := e.pco.teamclient.DefaultApi.OrganizationsOrgIdTeamsGet(authctx, masterid).Offset(int32(offset)).Limit(int32(limit)).Execute()
+		if err != nil {
+			return envCloneRequestError(httpr, err, "get teams")
+		}
+		httpr.Body.Close()
+		page := res.GetData()
+		for _, t := range page {
+			if len(t.GetAncestorTeamIds()) == 0 {
+				continue
+			}
+			teams = append(teams, map[string]interface{}{
+				"id":    t.GetTeamId(),
+				"name":  t.GetTeamName(),
+				"depth": len(t.GetAncestorTeamIds()),
+			})
+		}
+		offset += len(page)
+		if len(page) < limit || offset >= int(res.GetTotal()) {
+			break
+		}
+	}
+	// parents come first in the generated configuration
+	sort.SliceStable(teams, func(i, j int) bool {
+		return teams[i]["depth"].(int) < teams[j]["depth"].(int)
+	})
+
+	for _, t := range teams {
+		teamid := t["id"].(string)
+		if r := e.export("anypoint_team", t["name"].(string), ComposeResourceId([]string{masterid, teamid})); r != nil {
+			e.refs[teamid] = r.address() + ".id"
+		}
+		if !e.selected("anypoint_team_roles") {
+			continue
+		}
+		importid := masterid + "_" + teamid + "_roles"
+		d, err := e.read("anypoint_team_roles", importid)
+		if err != nil {
+			e.warn("unable to read anypoint_team_roles %s: %s", importid, err)
+			continue
+		}
+		// the roles are required, teams without roles are left out
+		if len(d.Get("roles").([]interface{})) > 0 {
+			e.add("anypoint_team_roles", t["name"].(string), importid, d)
+		}
+	}
+	return nil
+}
+
+// exports the vpcs owned by the business group and their load balancers
+func (e *orgExporter) crawlVPCs(orgid string) error {
+	res, httpr, err := e.pco.vpcclient.DefaultApi.OrganizationsOrgIdVpcsGet(getVPCAuthCtx(e.ctx, &e.pco), orgid).Execute()
+	if err != nil {
+		if isExportUnavailable(httpr) {
+			e.warn("unable to list the vpcs of business group %s, status %d", orgid, httpr.StatusCode)
+			return nil
+		}
+		return envCloneRequestError(httpr, err, "get vpcs of business group "+orgid)
+	}
+	httpr.Body.Close()
+
+	for _, v := range res.GetData() {
+		// the vpcs shared with the business group are exported with their owner
+		if v.GetOwnerId() != orgid {
+			continue
+		}
+		vpcid := v.GetId()
+		if r := e.export("anypoint_vpc", v.GetName(), ComposeResourceId([]string{orgid, vpcid})); r != nil {
+			e.refs[vpcid] = r.address() + ".id"
+		}
+		if !e.selected("anypoint_dlb") {
+			continue
+		}
+		dlbs, httpr, err := e.pco.dlbclient.DefaultApi.OrganizationsOrgIdVpcsVpcIdLoadbalancersGet(getDLBAuthCtx(e.ctx, &e.pco), orgid, vpcid).Execute()
+		if err != nil {
+			if isExportUnavailable(httpr) {
+				e.warn("unable to list the load balancers of vpc %s, status %d", vpcid, httpr.StatusCode)
+				continue
+			}
+			return envCloneRequestError(httpr, err, "get load balancers of vpc "+vpcid)
+		}
+		httpr.Body.Close()
+		for _, l := range dlbs.GetData() {
+			e.export("anypoint_dlb", l.GetName(), ComposeResourceId([]string{orgid, vpcid, l.GetId()}))
+		}
+	}
+	return nil
+}
+
+// exports the queues and exchanges of the environment in every region where MQ is enabled
+func (e *orgExporter) crawlDestinations(orgid string, envid string, envname string) error {
+	for _, regionid := range ENV_MQ_REGIONS {
+		if e.selected("anypoint_amq") {
+			queues, skip, warning, err := listENVCloneDestinations(e.ctx, &e.pco, orgid, envid, regionid, "queue")
+			if err != nil {
+				return err
+			}
+			if warning != "" {
+				e.warn("%s", warning)
+			}
+			if skip {
+				continue
+			}
+			// the dead letter queues are referenced within the region
+			regionrefs := make(map[string]string)
+			exported := make([]*exportedResource, 0)
+			for _, q := range queues {
+				queueid := q.GetQueueId()
+				if r := e.export("anypoint_amq", joinExportName(envname, queueid), ComposeResourceId([]string{orgid, envid, regionid, queueid})); r != nil {
+					regionrefs[queueid] = r.address() + ".queue_id"
+					exported = append(exported, r)
+				}
+			}
+			for _, r := range exported {
+				for queueid, ref := range regionrefs {
+					if queueid != r.data.Get("queue_id").(string) {
+						r.refs[queueid] = ref
+					}
+				}
+			}
+		}
+		if e.selected("anypoint_ame") {
+			exchanges, skip, warning, err := listENVCloneDestinations(e.ctx, &e.pco, orgid, envid, regionid, "exchange")
+			if err != nil {
+				return err
+			}
+			if warning != "" {
+				e.warn("%s", warning)
+			}
+			if skip {
+				continue
+			}
+			for _, x := range exchanges {
+				exchangeid := x.GetExchangeId()
+				e.export("anypoint_ame", joinExportName(envname, exchangeid), ComposeResourceId([]string{orgid, envid, regionid, exchangeid}))
+			}
+		}
+	}
+	return nil
+}
+
+/*
+Reads the resource the way terraform imports it and adds it to the exported resources.
+Returns nil when the resource type is not exported or the resource could not be read.
+*/
+func (e *orgExporter) export(restype string, name string, importid string) *exportedResource {
+	if !e.selected(restype) {
+		return nil
+	}
+	d, err := e.read(restype, importid)
+	if err != nil {
+		e.warn("unable to read %s %s: %s", restype, importid, err)
+		return nil
+	}
+	return e.add(restype, name, importid, d)
+}
+
+// runs the resource's importer and read from the import id
+func (e *orgExporter) read(restype string, importid string) (*schema.ResourceData, error) {
+	res := e.provider.ResourcesMap[restype]
+	d := res.Data(nil)
+	d.SetId(importid)
+	if res.Importer != nil && res.Importer.StateContext != nil {
+		list, err := res.Importer.StateContext(e.ctx, d, e.pco)
+		if err != nil {
+			return nil, err
+		}
+		d = list[0]
+	}
+	if diags := res.ReadContext(e.ctx, d, e.pco); diags.HasError() {
+		return nil, exportDiagsError(diags)
+	}
+	if d.Id() == "" {
+		return nil, fmt.Errorf("not found")
+	}
+	return d, nil
+}
+
+func (e *orgExporter) add(restype string, name string, importid string, d *schema.ResourceData) *exportedResource {
+	r := &exportedResource{
+		resourceType: restype,
+		name:         e.resourceName(restype, name),
+		importId:     importid,
+		data:         d,
+		refs:         make(map[string]string),
+	}
+	e.resources = append(e.resources, r)
+	e.result.Resources[restype]++
+	return r
+}
+
+func (e *orgExporter) selected(restype string) bool {
+	return len(e.types) == 0 || StringInSlice(e.types, restype, false)
+}
+
+func (e *orgExporter) warn(format string, args ...interface{}) {
+	e.result.Warnings = append(e.result.Warnings, fmt.Sprintf(format, args...))
+}
+
+// returns a valid terraform identifier from the given name, unique among the resources of the same type
+func (e *orgExporter) resourceName(restype string, name string) string {
+	n := strings.Trim(exportNameInvalidChars.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if n == "" {
+		n = strings.TrimPrefix(restype, "anypoint_")
+	}
+	if n[0] >= '0' && n[0] <= '9' {
+		n = "_" + n
+	}
+	names, ok := e.names[restype]
+	if !ok {
+		names = make(map[string]bool)
+		e.names[restype] = names
+	}
+	unique := n
+	for i := 2; names[unique]; i++ {
+		unique = n + "_" + strconv.Itoa(i)
+	}
+	names[unique] = true
+	return unique
+}
+
+// writes one file per resource type, the import blocks, the variables and the provider requirements
+func (e *orgExporter) write(dir string, orgid string) error {
+	files := make(map[string][]byte)
+	filenames := make([]string, 0)
+	addFile := func(name string, content []byte) {
+		files[name] = content
+		filenames = append(filenames, name)
+	}
+
+	versions := newHCLWriter()
+	versions.openBlock("terraform")
+	versions.attribute("required_version", hclQuote(">= 1.5.0"))
+	versions.openBlock("required_providers")
+	versions.attribute("anypoint", "{ source = "+hclQuote(EXPORT_PROVIDER_SOURCE)+" }")
+	versions.closeBlock()
+	versions.closeBlock()
+	addFile("versions.tf", versions.Bytes())
+
+	variables := newHCLWriter()
+	variables.openBlock("variable " + hclQuote(EXPORT_ROOT_ORG_VARIABLE))
+	variables.attribute("type", "string")
+	variables.attribute("description", hclQuote("The id of the exported business group."))
+	variables.attribute("default", hclQuote(orgid))
+	variables.closeBlock()
+	addFile("variables.tf", variables.Bytes())
+
+	imports := newHCLWriter()
+	for _, restype := range EXPORT_RESOURCE_TYPES {
+		w := newHCLWriter()
+		for _, r := range e.resources {
+			if r.resourceType != restype {
+				continue
+			}
+			e.writeResource(w, r)
+			imports.openBlock("import")
+			imports.attribute("to", r.address())
+			imports.attribute("id", hclQuote(r.importId))
+			imports.closeBlock()
+			imports.newline()
+		}
+		if e.result.Resources[restype] > 0 {
+			addFile(strings.TrimPrefix(restype, "anypoint_")+".tf", w.Bytes())
+		}
+	}
+	addFile("imports.tf", imports.Bytes())
+
+	// existing configuration is never overwritten
+	for _, name := range filenames {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return fmt.Errorf("the file %s already exists", filepath.Join(dir, name))
+		}
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for _, name := range filenames {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), files[name], 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *orgExporter) writeResource(w *hclWriter, r *exportedResource) {
+	res := e.provider.ResourcesMap[r.resourceType]
+	w.openBlock("resource " + hclQuote(r.resourceType) + " " + hclQuote(r.name))
+	e.writeBody(w, r, res.Schema, r.data.Get)
+	w.closeBlock()
+	w.newline()
+}
+
+/*
+Writes the configurable attributes of the schema which values differ from their default,
+the attributes come first in alphabetical order followed by the nested blocks.
+*/
+func (e *orgExporter) writeBody(w *hclWriter, r *exportedResource, s map[string]*schema.Schema, get func(string) interface{}) {
+	keys := make([]string, 0, len(s))
+	for k := range s {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	written := make(map[string]bool)
+	blocks := make([]string, 0)
+	for _, k := range keys {
+		sch := s[k]
+		if !isExportedAttribute(k, sch) {
+			continue
+		}
+		conflict := false
+		for _, c := range sch.ConflictsWith {
+			conflict = conflict || written[c]
+		}
+		if conflict {
+			continue
+		}
+		// the secrets are not written to the configuration
+		if sch.Sensitive {
+			if sch.Required {
+				w.comment(k + " is sensitive, its value is not exported")
+				w.attribute(k, hclQuote(""))
+				written[k] = true
+			}
+			continue
+		}
+		v := get(k)
+		if !sch.Required && isExportDefault(sch, v) {
+			continue
+		}
+		written[k] = true
+		if _, ok := sch.Elem.(*schema.Resource); ok {
+			blocks = append(blocks, k)
+			continue
+		}
+		w.attribute(k, e.expression(r, v))
+	}
+
+	for _, k := range blocks {
+		elem := s[k].Elem.(*schema.Resource)
+		for _, item := range exportListItems(get(k)) {
+			m, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			w.openBlock(k)
+			e.writeBody(w, r, elem.Schema, func(key string) interface{} {
+				return m[key]
+			})
+			w.closeBlock()
+		}
+	}
+}
+
+// returns the HCL expression of the value, the known ids are replaced by their reference
+func (e *orgExporter) expression(r *exportedResource, v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		if ref, ok := r.refs[t]; ok {
+			return ref
+		}
+		// a resource never references itself
+		if ref, ok := e.refs[t]; ok && !strings.HasPrefix(ref, r.address()+".") {
+			return ref
+		}
+		return hclQuote(t)
+	case bool:
+		return strconv.FormatBool(t)
+	case int:
+		return strconv.Itoa(t)
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case *schema.Set:
+		return e.expression(r, t.List())
+	case []interface{}:
+		items := make([]string, len(t))
+		for i, item := range t {
+			items[i] = e.expression(r, item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case map[string]interface{}:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		items := make([]string, len(keys))
+		for i, k := range keys {
+			items[i] = hclQuote(k) + " = " + e.expression(r, t[k])
+		}
+		if len(items) == 0 {
+			return "{}"
+		}
+		return "{ " + strings.Join(items, ", ") + " }"
+	}
+	return hclQuote(fmt.Sprint(v))
+}
+
+func (r *exportedResource) address() string {
+	return r.resourceType + "." + r.name
+}
+
+// only the configurable and maintained attributes are exported
+func isExportedAttribute(key string, sch *schema.Schema) bool {
+	if key == "id" || key == "last_updated" {
+		return false
+	}
+	return (sch.Required || sch.Optional) && sch.Deprecated == ""
+}
+
+// whether the value is the attribute's default or its zero value when it has no default
+func isExportDefault(sch *schema.Schema, v interface{}) bool {
+	switch t := v.(type) {
+	case nil:
+		return true
+	case *schema.Set:
+		return t.Len() == 0
+	case []interface{}:
+		return len(t) == 0
+	case map[string]interface{}:
+		return len(t) == 0
+	}
+	if sch.Default != nil {
+		return fmt.Sprint(sch.Default) == fmt.Sprint(v)
+	}
+	switch t := v.(type) {
+	case string:
+		return t == ""
+	case int:
+		return t == 0
+	case float64:
+		return t == 0
+	case bool:
+		return !t
+	}
+	return false
+}
+
+func exportListItems(v interface{}) []interface{} {
+	switch t := v.(type) {
+	case *schema.Set:
+		return t.List()
+	case []interface{}:
+		return t
+	}
+	return nil
+}
+
+// joins the non empty parts of a resource name
+func joinExportName(parts ...string) string {
+	res := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p != "" {
+			res = append(res, p)
+		}
+	}
+	return strings.Join(res, "_")
+}
+
+// the listings answering with a client error other than unauthorized are reported and skipped
+func isExportUnavailable(httpr *http.Response) bool {
+	return httpr != nil && httpr.StatusCode >= 400 && httpr.StatusCode < 500 && httpr.StatusCode != http.StatusUnauthorized
+}
+
+func exportDiagsError(diags diag.Diagnostics) error {
+	msgs := make([]string, 0, len(diags))
+	for _, d := range diags {
+		if d.Severity != diag.Error {
+			continue
+		}
+		msg := d.Summary
+		if d.Detail != "" {
+			msg += ": " + d.Detail
+		}
+		msgs = append(msgs, msg)
+	}
+	return fmt.Errorf("%s", strings.Join(msgs, "; "))
+}
+
+// a minimal writer of terraform configuration, the consecutive attributes are aligned like terraform fmt does
+type hclWriter struct {
+	buf     bytes.Buffer
+	depth   int
+	pending [][2]string
+}
+
+func newHCLWriter() *hclWriter {
+	return &hclWriter{}
+}
+
+func (w *hclWriter) attribute(name string, expr string) {
+	w.pending = append(w.pending, [2]string{name, expr})
+}
+
+func (w *hclWriter) comment(text string) {
+	w.pending = append(w.pending, [2]string{"", "# " + text})
+}
+
+func (w *hclWriter) openBlock(header string) {
+	w.flush()
+	w.line(header + " {")
+	w.depth++
+}
+
+func (w *hclWriter) closeBlock() {
+	w.flush()
+	w.depth--
+	w.line("}")
+}
+
+func (w *hclWriter) newline() {
+	w.flush()
+	w.buf.WriteString("\n")
+}
+
+func (w *hclWriter) Bytes() []byte {
+	w.flush()
+	return w.buf.Bytes()
+}
+
+func (w *hclWriter) line(s string) {
+	w.buf.WriteString(strings.Repeat("  ", w.depth) + s + "\n")
+}
+
+func (w *hclWriter) flush() {
+	width := 0
+	for _, a := range w.pending {
+		if len(a[0]) > width {
+			width = len(a[0])
+		}
+	}
+	for _, a := range w.pending {
+		if a[0] == "" {
+			w.line(a[1])
+			continue
+		}
+		w.line(a[0] + strings.Repeat(" ", width-len(a[0])) + " = " + a[1])
+	}
+	w.pending = nil
+}
+
+// quotes the string as an HCL literal, the template sequences are escaped
+func hclQuote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i, c := range s {
+		switch c {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '$', '%':
+			if i+1 < len(s) && s[i+1] == '{' {
+				b.WriteRune(c)
+			}
+			b.WriteRune(c)
+		default:
+			if c < 0x20 {
+				fmt.Fprintf(&b, `\u%04x`, c)
+			} else {
+				b.WriteRune(c)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
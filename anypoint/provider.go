@@ -68,10 +68,46 @@ func Provider() *schema.Provider {
 				DefaultFunc: schema.EnvDefaultFunc("ANYPOINT_PASSWORD", nil),
 				Description: "the user's password",
 			},
+			"jwt_private_key": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Sensitive:     true,
+				DefaultFunc:   schema.EnvDefaultFunc("ANYPOINT_JWT_PRIVATE_KEY", nil),
+				ConflictsWith: []string{"jwt_private_key_file"},
+				Description:   "the PEM encoded private key used to sign the connected app's JWT bearer assertion",
+			},
+			"jwt_private_key_file": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				DefaultFunc:   schema.EnvDefaultFunc("ANYPOINT_JWT_PRIVATE_KEY_FILE", nil),
+				ConflictsWith: []string{"jwt_private_key"},
+				Description:   "the path of the PEM file containing the private key used to sign the connected app's JWT bearer assertion",
+			},
+			"jwt_subject": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("ANYPOINT_JWT_SUBJECT", nil),
+				Description: "the username of the user the connected app acts on behalf of when using the JWT bearer grant",
+			},
+			"oidc_token": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Sensitive:     true,
+				DefaultFunc:   schema.EnvDefaultFunc("ANYPOINT_OIDC_TOKEN", nil),
+				ConflictsWith: []string{"oidc_token_file"},
+				Description:   "the OIDC identity token (issued by a CI platform for instance) to exchange for an access token",
+			},
+			"oidc_token_file": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				DefaultFunc:   schema.EnvDefaultFunc("ANYPOINT_OIDC_TOKEN_FILE", nil),
+				ConflictsWith: []string{"oidc_token"},
+				Description:   "the path of the file containing the OIDC identity token to exchange for an access token",
+			},
 			"cplane": {
 				Type:        schema.TypeString,
 				Optional:    true,
-				DefaultFunc: schema.EnvDefaultFunc("ANYPOINT_CPLANE", "us"),
+				DefaultFunc: schema.EnvDefaultFunc("ANYPOINT_CPLANE", nil),
 				ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
 					v := val.(string)
 					if v != "us" && v != "eu" && v != "gov" {
@@ -79,55 +115,79 @@ func Provider() *schema.Provider {
 					}
 					return
 				},
-				Description: "the anypoint control plane",
+				Description: "the anypoint control plane, defaults to the profile's cplane or 'us'",
+			},
+			"profile": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("ANYPOINT_PROFILE", nil),
+				Description: "the name of the credentials profile to use, defaults to 'default'",
+			},
+			"credentials_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("ANYPOINT_CREDENTIALS_FILE", nil),
+				Description: "the path of the credentials file holding the profiles, defaults to '~/.anypoint/credentials'",
 			},
 		},
 		ResourcesMap: map[string]*schema.Resource{
 			"anypoint_vpc":                 resourceVPC(),
 			"anypoint_vpn":                 resourceVPN(),
 			"anypoint_bg":                  resourceBG(),
+			"anypoint_bg_property":         resourceBGProperty(),
 			"anypoint_rolegroup_roles":     resourceRoleGroupRoles(),
 			"anypoint_rolegroup":           resourceRoleGroup(),
 			"anypoint_env":                 resourceENV(),
+			"anypoint_env_clone":           resourceENVClone(),
 			"anypoint_user":                resourceUser(),
+			"anypoint_users_bulk":          resourceUsersBulk(),
 			"anypoint_user_rolegroup":      resourceUserRolegroup(),
 			"anypoint_team":                resourceTeam(),
 			"anypoint_team_roles":          resourceTeamRoles(),
 			"anypoint_team_member":         resourceTeamMember(),
 			"anypoint_team_group_mappings": resourceTeamGroupMappings(),
+			"anypoint_team_group_mapping":  resourceTeamGroupMapping(),
 			"anypoint_dlb":                 resourceDLB(),
 			"anypoint_idp_oidc":            resourceOIDC(),
 			"anypoint_idp_saml":            resourceSAML(),
 			"anypoint_connected_app":       resourceConnectedApp(),
+			"anypoint_connected_app_scope": resourceConnectedAppScope(),
+			"anypoint_connected_app_key":   resourceConnectedAppKey(),
+			"anypoint_org_security_policy": resourceOrgSecurityPolicy(),
 			"anypoint_amq":                 resourceAMQ(),
 			"anypoint_ame":                 resourceAME(),
 			"anypoint_ame_binding":         resourceAMEBinding(),
 		},
 		DataSourcesMap: map[string]*schema.Resource{
-			"anypoint_vpcs":                dataSourceVPCs(),
-			"anypoint_vpc":                 dataSourceVPC(),
-			"anypoint_vpn":                 dataSourceVPN(),
-			"anypoint_bg":                  dataSourceBG(),
-			"anypoint_roles":               dataSourceRoles(),
-			"anypoint_rolegroup":           dataSourceRoleGroup(),
-			"anypoint_rolegroups":          dataSourceRoleGroups(),
-			"anypoint_users":               dataSourceUsers(),
-			"anypoint_user":                dataSourceUser(),
-			"anypoint_env":                 dataSourceENV(),
-			"anypoint_user_rolegroup":      dataSourceUserRolegroup(),
-			"anypoint_user_rolegroups":     dataSourceUserRolegroups(),
-			"anypoint_team":                dataSourceTeam(),
-			"anypoint_teams":               dataSourceTeams(),
-			"anypoint_team_roles":          dataSourceTeamRoles(),
-			"anypoint_team_members":        dataSourceTeamMembers(),
-			"anypoint_team_group_mappings": dataSourceTeamGroupMappings(),
-			"anypoint_dlb":                 dataSourceDLB(),
-			"anypoint_dlbs":                dataSourceDLBs(),
-			"anypoint_idp":                 dataSourceIDP(),
-			"anypoint_idps":                dataSourceIDPs(),
-			"anypoint_connected_app":       dataSourceConnectedApp(),
-			"anypoint_amq":                 dataSourceAMQ(),
-			"anypoint_ame":                 dataSourceAME(),
+			"anypoint_vpcs":                 dataSourceVPCs(),
+			"anypoint_vpc":                  dataSourceVPC(),
+			"anypoint_vpn":                  dataSourceVPN(),
+			"anypoint_bg":                   dataSourceBG(),
+			"anypoint_bgs":                  dataSourceBGs(),
+			"anypoint_roles":                dataSourceRoles(),
+			"anypoint_rolegroup":            dataSourceRoleGroup(),
+			"anypoint_rolegroups":           dataSourceRoleGroups(),
+			"anypoint_users":                dataSourceUsers(),
+			"anypoint_user":                 dataSourceUser(),
+			"anypoint_env":                  dataSourceENV(),
+			"anypoint_envs":                 dataSourceENVs(),
+			"anypoint_user_rolegroup":       dataSourceUserRolegroup(),
+			"anypoint_user_rolegroups":      dataSourceUserRolegroups(),
+			"anypoint_team":                 dataSourceTeam(),
+			"anypoint_teams":                dataSourceTeams(),
+			"anypoint_team_roles":           dataSourceTeamRoles(),
+			"anypoint_team_members":         dataSourceTeamMembers(),
+			"anypoint_team_group_mappings":  dataSourceTeamGroupMappings(),
+			"anypoint_dlb":                  dataSourceDLB(),
+			"anypoint_dlbs":                 dataSourceDLBs(),
+			"anypoint_idp":                  dataSourceIDP(),
+			"anypoint_idps":                 dataSourceIDPs(),
+			"anypoint_idp_sp_metadata":      dataSourceIDPSPMetadata(),
+			"anypoint_connected_app":        dataSourceConnectedApp(),
+			"anypoint_connected_app_scopes": dataSourceConnectedAppScopes(),
+			"anypoint_org_usage":            dataSourceOrgUsage(),
+			"anypoint_amq":                  dataSourceAMQ(),
+			"anypoint_ame":                  dataSourceAME(),
 		},
 		ConfigureContextFunc: providerConfigure,
 		TerraformVersion:     "v1.0.1",
@@ -144,10 +204,81 @@ func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}
 	username := d.Get("username").(string)
 	password := d.Get("password").(string)
 	cplane := d.Get("cplane").(string)
+	jwt_subject := d.Get("jwt_subject").(string)
+	jwt_private_key := d.Get("jwt_private_key").(string)
+	jwt_private_key_file := d.Get("jwt_private_key_file").(string)
+	oidc_token := d.Get("oidc_token").(string)
+	oidc_token_file := d.Get("oidc_token_file").(string)
+
+	// the credentials profile only applies when no credentials are given through the provider's arguments
+	// or their environment variables, the cplane argument takes precedence over the profile's
+	profile, err := loadCredentialsProfile(d.Get("credentials_file").(string), d.Get("profile").(string))
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to load the credentials profile",
+			Detail:   err.Error(),
+		})
+		return nil, diags
+	}
+	if profile != nil {
+		nocreds := true
+		for _, v := range []string{client_id, client_secret, access_token, username, password, jwt_private_key, jwt_private_key_file, oidc_token, oidc_token_file} {
+			if v != "" {
+				nocreds = false
+				break
+			}
+		}
+		if nocreds {
+			client_id = profile["client_id"]
+			client_secret = profile["client_secret"]
+			username = profile["username"]
+			password = profile["password"]
+			jwt_private_key_file = profile["jwt_private_key_file"]
+			if jwt_subject == "" {
+				jwt_subject = profile["jwt_subject"]
+			}
+		}
+		if cplane == "" {
+			cplane = profile["cplane"]
+		}
+	}
+	if cplane == "" {
+		cplane = "us"
+	}
+	if cplane2serverindex(cplane) < 0 {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unknown control plane " + cplane,
+			Detail:   "the control plane must be one of 'us', 'eu' or 'gov'.",
+		})
+		return nil, diags
+	}
+
+	jwt_private_key, err = loadProviderSecret(jwt_private_key, jwt_private_key_file)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to read the JWT private key file",
+			Detail:   err.Error(),
+		})
+		return nil, diags
+	}
+	oidc_token, err = loadProviderSecret(oidc_token, oidc_token_file)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to read the OIDC token file",
+			Detail:   err.Error(),
+		})
+		return nil, diags
+	}
 
 	server_index := cplane2serverindex(cplane)
 	auth_ctx := context.WithValue(ctx, auth.ContextServerIndex, server_index)
 
+	// authentication methods are tried in the following order:
+	// access token, username/password, JWT bearer, OIDC token exchange and client credentials
 	if access_token != "" {
 		return newProviderConfOutput(access_token, server_index), diags
 	}
@@ -160,6 +291,30 @@ func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}
 		return newProviderConfOutput(authres.GetAccessToken(), server_index), diags
 	}
 
+	if (client_id != "") && (jwt_private_key != "") {
+		if jwt_subject == "" {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Missing JWT subject",
+				Detail:   "jwt_subject is required to authenticate using the connected app JWT bearer grant.",
+			})
+			return newProviderConfOutput("", server_index), diags
+		}
+		authres, d := jwtBearerAuth(ctx, server_index, client_id, client_secret, jwt_subject, jwt_private_key)
+		if d != nil {
+			return newProviderConfOutput("", server_index), d
+		}
+		return newProviderConfOutput(authres.GetAccessToken(), server_index), diags
+	}
+
+	if (client_id != "") && (oidc_token != "") {
+		authres, d := oidcTokenExchangeAuth(ctx, server_index, client_id, client_secret, oidc_token)
+		if d != nil {
+			return newProviderConfOutput("", server_index), d
+		}
+		return newProviderConfOutput(authres.GetAccessToken(), server_index), diags
+	}
+
 	if (client_id != "") && (client_secret != "") {
 		authres, d := connectedAppAuth(auth_ctx, client_id, client_secret)
 		if d != nil {
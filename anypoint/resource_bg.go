@@ -2,23 +2,47 @@ package anypoint
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"io/ioutil"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 
 	org "github.com/mulesoft-anypoint/anypoint-client-go/org"
 )
 
 func resourceBG() *schema.Resource {
+	r := resourceBGV1()
+	r.SchemaVersion = 1
+	r.StateUpgraders = []schema.StateUpgrader{
+		{
+			Version: 0,
+			Type:    resourceBGV0().CoreConfigSchema().ImpliedType(),
+			Upgrade: resourceBGStateUpgradeV0,
+		},
+	}
+	return r
+}
+
+func resourceBGV1() *schema.Resource {
 	return &schema.Resource{
 		CreateContext: resourceBGCreate,
 		ReadContext:   resourceBGRead,
 		UpdateContext: resourceBGUpdate,
 		DeleteContext: resourceBGDelete,
+		CustomizeDiff: resourceBGCustomizeDiff,
 		Description: `
 		Creates a business group (org).
+		The entitlements assigned to the business group are validated at plan time against the capacity of the parent organization.
+		The business group can be moved in place to a different parent of the same master organization, the entitlements are then validated against the capacity of the new parent.
+		The business group can only be deleted when ` + "`" + `deletion_protection` + "`" + ` is disabled and none of its environments contains MQ destinations, applications or VPC associations.
 		`,
 		Schema: map[string]*schema.Schema{
 			"last_updated": {
@@ -40,7 +64,7 @@ func resourceBG() *schema.Resource {
 			"owner_id": {
 				Type:        schema.TypeString,
 				Required:    true,
-				Description: "The user id of the owner of this organization.",
+				Description: "The user id of the owner of this organization. Changing it transfers the ownership to a user of the master organization.",
 			},
 			"created_at": {
 				Type:        schema.TypeString,
@@ -73,8 +97,7 @@ func resourceBG() *schema.Resource {
 			"parent_organization_id": {
 				Type:        schema.TypeString,
 				Required:    true,
-				ForceNew:    true,
-				Description: "The immediate parent organization id of this organization.",
+				Description: "The immediate parent organization id of this organization. Changing it moves the business group and its descendants under the new parent.",
 			},
 			"parent_organization_ids": {
 				Type:     schema.TypeList,
@@ -136,9 +159,12 @@ func resourceBG() *schema.Resource {
 				Description: "The anypoint platform subscription expiration date.",
 			},
 			"properties": {
-				Type:        schema.TypeString,
-				Computed:    true,
-				Description: "The organiztion's general properties.",
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Description: "The organization's properties managed by terraform. Only the keys set here are reconciled, the other properties of the organization are left untouched. The values which are not strings are read as JSON. Do not set the keys managed by anypoint_bg_property.",
 			},
 			"environments": {
 				Type:        schema.TypeList,
@@ -179,29 +205,36 @@ func resourceBG() *schema.Resource {
 					},
 				},
 			},
+			"entitlements": resourceBGEntitlementsSchema(),
 			"entitlements_createenvironments": {
-				Type:        schema.TypeBool,
-				Optional:    true,
-				Default:     false,
-				Description: "Whether this organization can have additional environments.",
+				Type:          schema.TypeBool,
+				Optional:      true,
+				Default:       false,
+				Description:   "Whether this organization can have additional environments.",
+				Deprecated:    "use entitlements.0.create_environments instead",
+				ConflictsWith: []string{"entitlements"},
 				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
 					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "false")
 				},
 			},
 			"entitlements_globaldeployment": {
-				Type:        schema.TypeBool,
-				Optional:    true,
-				Default:     false,
-				Description: "Whether this organization can have global deployments.",
+				Type:          schema.TypeBool,
+				Optional:      true,
+				Default:       false,
+				Description:   "Whether this organization can have global deployments.",
+				Deprecated:    "use entitlements.0.global_deployment instead",
+				ConflictsWith: []string{"entitlements"},
 				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
 					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "false")
 				},
 			},
 			"entitlements_createsuborgs": {
-				Type:        schema.TypeBool,
-				Optional:    true,
-				Default:     true,
-				Description: "Whether this organization can create sub organizations (descendants).",
+				Type:          schema.TypeBool,
+				Optional:      true,
+				Default:       true,
+				Description:   "Whether this organization can create sub organizations (descendants).",
+				Deprecated:    "use entitlements.0.create_sub_orgs instead",
+				ConflictsWith: []string{"entitlements"},
 				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
 					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "true")
 				},
@@ -210,22 +243,27 @@ func resourceBG() *schema.Resource {
 				Type:        schema.TypeBool,
 				Computed:    true,
 				Description: "Whether this organization has hybrid enabled.",
+				Deprecated:  "read-only, not part of the entitlements block, use the entitlements_hybridenabled attribute of the anypoint_bg data source instead",
 			},
 			"entitlements_hybridinsight": {
 				Type:        schema.TypeBool,
 				Computed:    true,
 				Description: "Whether this organization has hybrid insight.",
+				Deprecated:  "read-only, not part of the entitlements block, use the entitlements_hybridinsight attribute of the anypoint_bg data source instead",
 			},
 			"entitlements_hybridautodiscoverproperties": {
 				Type:        schema.TypeBool,
 				Computed:    true,
 				Description: "Whether this organization has hybrid auto-discovery properties enabled",
+				Deprecated:  "read-only, not part of the entitlements block, use the entitlements_hybridautodiscoverproperties attribute of the anypoint_bg data source instead",
 			},
 			"entitlements_vcoresproduction_assigned": {
-				Type:        schema.TypeFloat,
-				Optional:    true,
-				Default:     0,
-				Description: "The number of production vcores assigned to this organization.",
+				Type:          schema.TypeFloat,
+				Optional:      true,
+				Default:       0,
+				Description:   "The number of production vcores assigned to this organization.",
+				Deprecated:    "use entitlements.0.vcores.0.production_assigned instead",
+				ConflictsWith: []string{"entitlements"},
 				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
 					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "0")
 				},
@@ -234,12 +272,15 @@ func resourceBG() *schema.Resource {
 				Type:        schema.TypeFloat,
 				Computed:    true,
 				Description: "The number of production vcores reassigned to this organization.",
+				Deprecated:  "use entitlements.0.vcores.0.production_reassigned instead",
 			},
 			"entitlements_vcoressandbox_assigned": {
-				Type:        schema.TypeFloat,
-				Optional:    true,
-				Default:     0,
-				Description: "The number of sandbox vcores assigned to this organization.",
+				Type:          schema.TypeFloat,
+				Optional:      true,
+				Default:       0,
+				Description:   "The number of sandbox vcores assigned to this organization.",
+				Deprecated:    "use entitlements.0.vcores.0.sandbox_assigned instead",
+				ConflictsWith: []string{"entitlements"},
 				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
 					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "0")
 				},
@@ -248,12 +289,15 @@ func resourceBG() *schema.Resource {
 				Type:        schema.TypeFloat,
 				Computed:    true,
 				Description: "The number of sandbox vcores reassigned to this organization.",
+				Deprecated:  "use entitlements.0.vcores.0.sandbox_reassigned instead",
 			},
 			"entitlements_vcoresdesign_assigned": {
-				Type:        schema.TypeFloat,
-				Optional:    true,
-				Default:     0,
-				Description: "The number of design vcores assigned to this organization.",
+				Type:          schema.TypeFloat,
+				Optional:      true,
+				Default:       0,
+				Description:   "The number of design vcores assigned to this organization.",
+				Deprecated:    "use entitlements.0.vcores.0.design_assigned instead",
+				ConflictsWith: []string{"entitlements"},
 				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
 					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "0")
 				},
@@ -262,12 +306,15 @@ func resourceBG() *schema.Resource {
 				Type:        schema.TypeFloat,
 				Computed:    true,
 				Description: "The number of design vcores reassigned to this organization.",
+				Deprecated:  "use entitlements.0.vcores.0.design_reassigned instead",
 			},
 			"entitlements_staticips_assigned": {
-				Type:        schema.TypeInt,
-				Optional:    true,
-				Default:     0,
-				Description: "The number of static IPs assigned to this organization.",
+				Type:          schema.TypeInt,
+				Optional:      true,
+				Default:       0,
+				Description:   "The number of static IPs assigned to this organization.",
+				Deprecated:    "use entitlements.0.network.0.static_ips_assigned instead",
+				ConflictsWith: []string{"entitlements"},
 				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
 					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "0")
 				},
@@ -276,12 +323,15 @@ func resourceBG() *schema.Resource {
 				Type:        schema.TypeInt,
 				Computed:    true,
 				Description: "The number of static IPs reassigned to this organization.",
+				Deprecated:  "use entitlements.0.network.0.static_ips_reassigned instead",
 			},
 			"entitlements_vpcs_assigned": {
-				Type:        schema.TypeInt,
-				Optional:    true,
-				Default:     0,
-				Description: "The number of VPCs assigned to this organization.",
+				Type:          schema.TypeInt,
+				Optional:      true,
+				Default:       0,
+				Description:   "The number of VPCs assigned to this organization.",
+				Deprecated:    "use entitlements.0.network.0.vpcs_assigned instead",
+				ConflictsWith: []string{"entitlements"},
 				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
 					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "0")
 				},
@@ -290,12 +340,15 @@ func resourceBG() *schema.Resource {
 				Type:        schema.TypeInt,
 				Computed:    true,
 				Description: "The number of VPCs reassigned to this organization.",
+				Deprecated:  "use entitlements.0.network.0.vpcs_reassigned instead",
 			},
 			"entitlements_vpns_assigned": {
-				Type:        schema.TypeInt,
-				Optional:    true,
-				Default:     0,
-				Description: "The number of VPNs assigned to this organization.",
+				Type:          schema.TypeInt,
+				Optional:      true,
+				Default:       0,
+				Description:   "The number of VPNs assigned to this organization.",
+				Deprecated:    "use entitlements.0.network.0.vpns_assigned instead",
+				ConflictsWith: []string{"entitlements"},
 				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
 					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "0")
 				},
@@ -304,148 +357,157 @@ func resourceBG() *schema.Resource {
 				Type:        schema.TypeInt,
 				Computed:    true,
 				Description: "The number of VPNs reassigned to this organization.",
+				Deprecated:  "use entitlements.0.network.0.vpns_reassigned instead",
 			},
 			"entitlements_workerloggingoverride_enabled": {
 				Type:        schema.TypeBool,
-				Optional:    true,
+				Computed:    true,
 				Description: "Whether the loggin override on workers is enabled for this organization.",
-				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
-					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "false") // default value of bool if not set is false
-				},
+				Deprecated:  "read-only, not part of the entitlements block, use the entitlements_workerloggingoverride_enabled attribute of the anypoint_bg data source instead",
 			},
 			"entitlements_mqmessages_base": {
-				Type:        schema.TypeInt,
-				Optional:    true,
-				Description: "The number of basic MQ messages assigned to this organization.",
+				Type:          schema.TypeInt,
+				Optional:      true,
+				Description:   "The number of basic MQ messages assigned to this organization.",
+				Deprecated:    "use entitlements.0.mq.0.messages_base instead",
+				ConflictsWith: []string{"entitlements"},
 				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
 					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "0") // default value of integers if not set is 0
 				},
 			},
 			"entitlements_mqmessages_addon": {
-				Type:        schema.TypeInt,
-				Optional:    true,
-				Description: "The number of MQ messages addons assigned to this organization.",
+				Type:          schema.TypeInt,
+				Optional:      true,
+				Description:   "The number of MQ messages addons assigned to this organization.",
+				Deprecated:    "use entitlements.0.mq.0.messages_addon instead",
+				ConflictsWith: []string{"entitlements"},
 				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
 					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "0") // default value of integers if not set is 0
 				},
 			},
 			"entitlements_mqrequests_base": {
-				Type:        schema.TypeInt,
-				Optional:    true,
-				Description: "The number of MQ requests base assigned to this organization.",
+				Type:          schema.TypeInt,
+				Optional:      true,
+				Description:   "The number of MQ requests base assigned to this organization.",
+				Deprecated:    "use entitlements.0.mq.0.requests_base instead",
+				ConflictsWith: []string{"entitlements"},
 				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
 					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "0") // default value of integers if not set is 0
 				},
 			},
 			"entitlements_mqrequests_addon": {
-				Type:        schema.TypeInt,
-				Optional:    true,
-				Description: "The number of MQ requests addon assigned to this organization.",
+				Type:          schema.TypeInt,
+				Optional:      true,
+				Description:   "The number of MQ requests addon assigned to this organization.",
+				Deprecated:    "use entitlements.0.mq.0.requests_addon instead",
+				ConflictsWith: []string{"entitlements"},
 				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
 					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "0") // default value of integers if not set is 0
 				},
 			},
 			"entitlements_objectstorerequestunits_base": {
-				Type:        schema.TypeInt,
-				Optional:    true,
-				Description: "The number of object store requests unists base for this organization.",
+				Type:          schema.TypeInt,
+				Optional:      true,
+				Description:   "The number of object store requests unists base for this organization.",
+				Deprecated:    "use entitlements.0.object_store.0.request_units_base instead",
+				ConflictsWith: []string{"entitlements"},
 				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
 					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "0") // default value of integers if not set is 0
 				},
 			},
 			"entitlements_objectstorerequestunits_addon": {
-				Type:        schema.TypeInt,
-				Optional:    true,
-				Description: "The number of object store requests units addon for this organization.",
+				Type:          schema.TypeInt,
+				Optional:      true,
+				Description:   "The number of object store requests units addon for this organization.",
+				Deprecated:    "use entitlements.0.object_store.0.request_units_addon instead",
+				ConflictsWith: []string{"entitlements"},
 				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
 					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "0") // default value of integers if not set is 0
 				},
 			},
 			"entitlements_objectstorekeys_base": {
-				Type:        schema.TypeInt,
-				Optional:    true,
-				Description: "The number of object store keys base for this organization.",
+				Type:          schema.TypeInt,
+				Optional:      true,
+				Description:   "The number of object store keys base for this organization.",
+				Deprecated:    "use entitlements.0.object_store.0.keys_base instead",
+				ConflictsWith: []string{"entitlements"},
 				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
 					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "0") // default value of integers if not set is 0
 				},
 			},
 			"entitlements_objectstorekeys_addon": {
-				Type:        schema.TypeInt,
-				Optional:    true,
-				Description: "The number of object store keys addon for this organization.",
+				Type:          schema.TypeInt,
+				Optional:      true,
+				Description:   "The number of object store keys addon for this organization.",
+				Deprecated:    "use entitlements.0.object_store.0.keys_addon instead",
+				ConflictsWith: []string{"entitlements"},
 				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
 					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "0") // default value of integers if not set is 0
 				},
 			},
 			"entitlements_mqadvancedfeatures_enabled": {
-				Type:        schema.TypeBool,
-				Optional:    true,
-				Description: "Whether the Anypoint MQ advanced features are enabled for this organization.",
+				Type:          schema.TypeBool,
+				Optional:      true,
+				Description:   "Whether the Anypoint MQ advanced features are enabled for this organization.",
+				Deprecated:    "use entitlements.0.mq.0.advanced_features_enabled instead",
+				ConflictsWith: []string{"entitlements"},
 				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
 					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "false") // default value of bool if not set is false
 				},
 			},
 			"entitlements_gateways_assigned": {
-				Type:        schema.TypeInt,
-				Optional:    true,
-				Description: "The number of gateways assigned to this organization.",
+				Type:          schema.TypeInt,
+				Optional:      true,
+				Description:   "The number of gateways assigned to this organization.",
+				Deprecated:    "use entitlements.0.api_manager.0.gateways_assigned instead",
+				ConflictsWith: []string{"entitlements"},
 				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
 					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "0") // default value of integers if not set is 0
 				},
 			},
 			"entitlements_designcenter_api": {
 				Type:        schema.TypeBool,
-				Optional:    true,
+				Computed:    true,
 				Description: "Whether te design center api is enabled for this organization.",
-				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
-					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "false") // default value of bool if not set is false
-				},
+				Deprecated:  "read-only, not part of the entitlements block, use the entitlements_designcenter_api attribute of the anypoint_bg data source instead",
 			},
 			"entitlements_designcenter_mozart": {
 				Type:        schema.TypeBool,
-				Optional:    true,
+				Computed:    true,
 				Description: "Whether the design center mozart is enabled for this organization.",
-				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
-					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "false") // default value of bool if not set is false
-				},
+				Deprecated:  "read-only, not part of the entitlements block, use the entitlements_designcenter_mozart attribute of the anypoint_bg data source instead",
 			},
 			"entitlements_partnersproduction_assigned": {
 				Type:        schema.TypeInt,
-				Optional:    true,
+				Computed:    true,
 				Description: "The number of partners production vcores assigned to this organization.",
-				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
-					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "0") // default value of integers if not set is 0
-				},
+				Deprecated:  "read-only, not part of the entitlements block, use the entitlements_partnersproduction_assigned attribute of the anypoint_bg data source instead",
 			},
 			"entitlements_partnerssandbox_assigned": {
 				Type:        schema.TypeInt,
-				Optional:    true,
+				Computed:    true,
 				Description: "The number of partners sandbox vcores assigned to this organization.",
-				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
-					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "0") // default value of integers if not set is 0
-				},
+				Deprecated:  "read-only, not part of the entitlements block, use the entitlements_partnerssandbox_assigned attribute of the anypoint_bg data source instead",
 			},
 			"entitlements_tradingpartnersproduction_assigned": {
 				Type:        schema.TypeInt,
-				Optional:    true,
+				Computed:    true,
 				Description: "The number of traded partners production vcores assigned to this organization.",
-				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
-					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "0") // default value of integers if not set is 0
-				},
+				Deprecated:  "read-only, not part of the entitlements block, use the entitlements_tradingpartnersproduction_assigned attribute of the anypoint_bg data source instead",
 			},
 			"entitlements_tradingpartnerssandbox_assigned": {
 				Type:        schema.TypeInt,
-				Optional:    true,
+				Computed:    true,
 				Description: "The number of traded partners sandbox vcores assigned to this organization.",
-				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
-					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "0") // default value of integers if not set is 0
-				},
+				Deprecated:  "read-only, not part of the entitlements block, use the entitlements_tradingpartnerssandbox_assigned attribute of the anypoint_bg data source instead",
 			},
 			"entitlements_loadbalancer_assigned": {
-				Type:        schema.TypeInt,
-				Optional:    true,
-				Default:     0,
-				Description: "The number of dedicated load balancers (DLB) assigned to this organization.",
+				Type:          schema.TypeInt,
+				Optional:      true,
+				Default:       0,
+				Description:   "The number of dedicated load balancers (DLB) assigned to this organization.",
+				Deprecated:    "use entitlements.0.network.0.load_balancers_assigned instead",
+				ConflictsWith: []string{"entitlements"},
 				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
 					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "0") // default value of integers if not set is 0
 				},
@@ -454,35 +516,31 @@ func resourceBG() *schema.Resource {
 				Type:        schema.TypeInt,
 				Computed:    true,
 				Description: "The number of dedicated load balancers (DLB) reassigned to this organization.",
+				Deprecated:  "use entitlements.0.network.0.load_balancers_reassigned instead",
 			},
 			"entitlements_externalidentity": {
 				Type:        schema.TypeBool,
-				Optional:    true,
+				Computed:    true,
 				Description: "Whether an external identity provider (IDP) was assigned to this organization.",
-				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
-					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "false") // default value of bool if not set is false
-				},
+				Deprecated:  "read-only, not part of the entitlements block, use the entitlements_externalidentity attribute of the anypoint_bg data source instead",
 			},
 			"entitlements_autoscaling": {
 				Type:        schema.TypeBool,
-				Optional:    true,
+				Computed:    true,
 				Description: "Whether autoscaling is enabled for this organization",
-				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
-					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "false") // default value of bool if not set is false
-				},
+				Deprecated:  "read-only, not part of the entitlements block, use the entitlements_autoscaling attribute of the anypoint_bg data source instead",
 			},
 			"entitlements_armalerts": {
 				Type:        schema.TypeBool,
-				Optional:    true,
+				Computed:    true,
 				Description: "Whether arm alerts are enabled for this organization.",
-				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
-					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "false") // default value of bool if not set is false
-				},
+				Deprecated:  "read-only, not part of the entitlements block, use the entitlements_armalerts attribute of the anypoint_bg data source instead",
 			},
 			"entitlements_apis_enabled": {
 				Type:        schema.TypeBool,
 				Optional:    true,
 				Description: "whether APIs are enabled for this organization.",
+				Deprecated:  "use entitlements.0.api_manager.0.apis_enabled instead",
 				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
 					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "false") // default value of bool if not set is false
 				},
@@ -491,6 +549,7 @@ func resourceBG() *schema.Resource {
 				Type:        schema.TypeInt,
 				Optional:    true,
 				Description: "The number of api monitoring schedules for this organization.",
+				Deprecated:  "use entitlements.0.api_manager.0.api_monitoring_schedules instead",
 				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
 					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "0") // default value of integers if not set is 0
 				},
@@ -499,179 +558,141 @@ func resourceBG() *schema.Resource {
 				Type:        schema.TypeBool,
 				Optional:    true,
 				Description: "Whether api community manager is enabled for this organization.",
+				Deprecated:  "use entitlements.0.api_manager.0.api_community_manager_enabled instead",
 				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
 					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "false") // default value of bool if not set is false
 				},
 			},
 			"entitlements_monitoringcenter_productsku": {
 				Type:        schema.TypeInt,
-				Optional:    true,
+				Computed:    true,
 				Description: "The number of monitoring center products sku for this organization.",
-				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
-					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "0") // default value of integers if not set is 0
-				},
+				Deprecated:  "read-only, not part of the entitlements block, use the entitlements_monitoringcenter_productsku attribute of the anypoint_bg data source instead",
 			},
 			"entitlements_apiquery_enabled": {
 				Type:        schema.TypeBool,
 				Optional:    true,
 				Description: "Whether api queries are enabled for this organization.",
+				Deprecated:  "use entitlements.0.api_manager.0.api_query_enabled instead",
 				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
 					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "false") // default value of bool if not set is false
 				},
 			},
 			"entitlements_apiquery_productsku": {
 				Type:        schema.TypeInt,
-				Optional:    true,
+				Computed:    true,
 				Description: "The number of api query product sku for this organization.",
-				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
-					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "0") // default value of integers if not set is 0
-				},
+				Deprecated:  "read-only, not part of the entitlements block, use the entitlements_apiquery_productsku attribute of the anypoint_bg data source instead",
 			},
 			"entitlements_apiqueryc360_enabled": {
 				Type:        schema.TypeBool,
-				Optional:    true,
+				Computed:    true,
 				Description: "Whether api query C360 is enabled for this organization.",
-				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
-					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "false") // default value of bool if not set is false
-				},
+				Deprecated:  "read-only, not part of the entitlements block, use the entitlements_apiqueryc360_enabled attribute of the anypoint_bg data source instead",
 			},
 			"entitlements_anggovernance_level": {
-				Type:     schema.TypeInt,
-				Optional: true,
-				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
-					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "0") // default value of integers if not set is 0
-				},
+				Type:       schema.TypeInt,
+				Computed:   true,
+				Deprecated: "read-only, not part of the entitlements block, use the entitlements_anggovernance_level attribute of the anypoint_bg data source instead",
 			},
 			"entitlements_crowd_hideapimanagerdesigner": {
-				Type:     schema.TypeBool,
-				Optional: true,
-				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
-					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "false") // default value of bool if not set is false
-				},
+				Type:       schema.TypeBool,
+				Computed:   true,
+				Deprecated: "read-only, not part of the entitlements block, use the entitlements_crowd_hideapimanagerdesigner attribute of the anypoint_bg data source instead",
 			},
 			"entitlements_crowd_hideformerapiplatform": {
-				Type:     schema.TypeBool,
-				Optional: true,
-				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
-					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "false") // default value of bool if not set is false
-				},
+				Type:       schema.TypeBool,
+				Computed:   true,
+				Deprecated: "read-only, not part of the entitlements block, use the entitlements_crowd_hideformerapiplatform attribute of the anypoint_bg data source instead",
 			},
 			"entitlements_crowd_environments": {
-				Type:     schema.TypeBool,
-				Optional: true,
-				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
-					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "false") // default value of bool if not set is false
-				},
+				Type:       schema.TypeBool,
+				Computed:   true,
+				Deprecated: "read-only, not part of the entitlements block, use the entitlements_crowd_environments attribute of the anypoint_bg data source instead",
 			},
 			"entitlements_cam_enabled": {
 				Type:        schema.TypeBool,
-				Optional:    true,
+				Computed:    true,
 				Description: "Whether cam is enabled for this organization.",
-				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
-					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "false") // default value of bool if not set is false
-				},
+				Deprecated:  "read-only, not part of the entitlements block, use the entitlements_cam_enabled attribute of the anypoint_bg data source instead",
 			},
 			"entitlements_exchange2_enabled": {
 				Type:        schema.TypeBool,
-				Optional:    true,
+				Computed:    true,
 				Description: "Whether exchange v2 is enabled for this organization.",
-				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
-					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "false") // default value of bool if not set is false
-				},
+				Deprecated:  "read-only, not part of the entitlements block, use the entitlements_exchange2_enabled attribute of the anypoint_bg data source instead",
 			},
 			"entitlements_crowdselfservicemigration_enabled": {
 				Type:        schema.TypeBool,
-				Optional:    true,
+				Computed:    true,
 				Description: "Whether crow self service migration is enabled for this organization.",
-				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
-					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "false") // default value of bool if not set is false
-				},
+				Deprecated:  "read-only, not part of the entitlements block, use the entitlements_crowdselfservicemigration_enabled attribute of the anypoint_bg data source instead",
 			},
 			"entitlements_kpidashboard_enabled": {
 				Type:        schema.TypeBool,
-				Optional:    true,
+				Computed:    true,
 				Description: "Whether KPI dashboard is enabled for this organization.",
-				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
-					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "false") // default value of bool if not set is false
-				},
+				Deprecated:  "read-only, not part of the entitlements block, use the entitlements_kpidashboard_enabled attribute of the anypoint_bg data source instead",
 			},
 			"entitlements_pcf": {
 				Type:        schema.TypeBool,
-				Optional:    true,
+				Computed:    true,
 				Description: "Whether PCF is included for this organization.",
-				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
-					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "false") // default value of bool if not set is false
-				},
+				Deprecated:  "read-only, not part of the entitlements block, use the entitlements_pcf attribute of the anypoint_bg data source instead",
 			},
 			"entitlements_appviz": {
 				Type:        schema.TypeBool,
-				Optional:    true,
+				Computed:    true,
 				Description: "Whether the app vizualize if enabled for this organization.",
-				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
-					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "flase") // default value of bool if not set is false
-				},
+				Deprecated:  "read-only, not part of the entitlements block, use the entitlements_appviz attribute of the anypoint_bg data source instead",
 			},
 			"entitlements_runtimefabric": {
 				Type:        schema.TypeBool,
-				Optional:    true,
-				Default:     true,
+				Computed:    true,
 				Description: "Whether Runtime Fabrics (RTF) is enabled for this organization.",
-				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
-					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "true")
-				},
+				Deprecated:  "read-only, not part of the entitlements block, use the entitlements_runtimefabric attribute of the anypoint_bg data source instead",
 			},
 			"entitlements_anypointsecuritytokenization_enabled": {
 				Type:        schema.TypeBool,
-				Optional:    true,
+				Computed:    true,
 				Description: "whether Anypoint securirty tokenization is enabled for this organization.",
-				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
-					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "false") // default value of bool if not set is false
-				},
+				Deprecated:  "read-only, not part of the entitlements block, use the entitlements_anypointsecuritytokenization_enabled attribute of the anypoint_bg data source instead",
 			},
 			"entitlements_anypointsecurityedgepolicies_enabled": {
 				Type:        schema.TypeBool,
-				Optional:    true,
+				Computed:    true,
 				Description: "Whether Anypoint security edge policies is enabled for this organization.",
-				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
-					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "false") // default value of bool if not set is false
-				},
+				Deprecated:  "read-only, not part of the entitlements block, use the entitlements_anypointsecurityedgepolicies_enabled attribute of the anypoint_bg data source instead",
 			},
 			"entitlements_runtimefabriccloud_enabled": {
 				Type:        schema.TypeBool,
-				Optional:    true,
+				Computed:    true,
 				Description: "Whether Runtime Fabrics (RTF) is enabled for this organization.",
-				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
-					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "false") // default value of bool if not set is false
-				},
+				Deprecated:  "read-only, not part of the entitlements block, use the entitlements_runtimefabriccloud_enabled attribute of the anypoint_bg data source instead",
 			},
 			"entitlements_servicemesh_enabled": {
 				Type:        schema.TypeBool,
-				Optional:    true,
+				Computed:    true,
 				Description: "Whether Service Mesh is enabled for this organization.",
-				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
-					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "false") // default value of bool if not set is false
-				},
+				Deprecated:  "read-only, not part of the entitlements block, use the entitlements_servicemesh_enabled attribute of the anypoint_bg data source instead",
 			},
 			"entitlements_messaging_assigned": {
 				Type:        schema.TypeInt,
-				Optional:    true,
+				Computed:    true,
 				Description: "The number of messaging assigned to this organization.",
-				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
-					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "0") // default value of integers if not set is 0
-				},
+				Deprecated:  "read-only, not part of the entitlements block, use the entitlements_messaging_assigned attribute of the anypoint_bg data source instead",
 			},
 			"entitlements_workerclouds_assigned": {
 				Type:        schema.TypeInt,
-				Optional:    true,
+				Computed:    true,
 				Description: "The number of worker clouds assigned to this organization",
-				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
-					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "0") // default value of integers if not set is 0
-				},
+				Deprecated:  "read-only, not part of the entitlements block, use the entitlements_workerclouds_assigned attribute of the anypoint_bg data source instead",
 			},
 			"entitlements_workerclouds_reassigned": {
 				Type:        schema.TypeInt,
 				Computed:    true,
 				Description: "The number of worker clouds reassigned to this organization",
+				Deprecated:  "read-only, not part of the entitlements block, use the entitlements_workerclouds_reassigned attribute of the anypoint_bg data source instead",
 			},
 			"owner_created_at": {
 				Type:        schema.TypeString,
@@ -760,6 +781,21 @@ func resourceBG() *schema.Resource {
 					return DiffSuppressFunc4OptionalPrimitives(k, old, new, d, "0") // default value of integeres if not set is 0
 				},
 			},
+			"skip_entitlements_validation": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "If true, the assigned entitlements are not validated against the capacity of the parent organization at plan time.",
+			},
+			"deletion_protection": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Whether the business group is protected against deletion.",
+			},
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 	}
 }
@@ -791,6 +827,33 @@ func resourceBGCreate(ctx context.Context, d *schema.ResourceData, m interface{}
 	defer httpr.Body.Close()
 
 	d.SetId(res.GetId())
+
+	if isBGAddonEntitlementsConfigured(d) {
+		if err := updateBGAddonEntitlements(ctx, &pco, res.GetId(), d); err != nil {
+			diags := append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Unable to set Business Group entitlements",
+				Detail:   err.Error(),
+			})
+			return diags
+		}
+	}
+
+	if props := d.Get("properties").(map[string]interface{}); len(props) > 0 {
+		if err := modifyOrgProperties(ctx, &pco, res.GetId(), func(current map[string]interface{}) {
+			for k, v := range props {
+				current[k] = v
+			}
+		}); err != nil {
+			diags := append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Unable to set Business Group properties",
+				Detail:   err.Error(),
+			})
+			return diags
+		}
+	}
+
 	resourceBGRead(ctx, d, m)
 
 	return diags
@@ -801,6 +864,8 @@ func resourceBGRead(ctx context.Context, d *schema.ResourceData, m interface{})
 	var diags diag.Diagnostics
 	pco := m.(ProviderConfOutput)
 	orgid := d.Id()
+	// only the properties managed by terraform are read
+	managed := d.Get("properties").(map[string]interface{})
 
 	authctx := getBGAuthCtx(ctx, &pco)
 
@@ -823,6 +888,7 @@ func resourceBGRead(ctx context.Context, d *schema.ResourceData, m interface{})
 	defer httpr.Body.Close()
 
 	orginstance := flattenBGData(&res)
+	orginstance["properties"] = flattenBGManagedProperties(getOrgProperties(&res), managed)
 
 	if err := setBGCoreAttributesToResourceData(d, orginstance); err != nil {
 		diags := append(diags, diag.Diagnostic{
@@ -832,6 +898,18 @@ func resourceBGRead(ctx context.Context, d *schema.ResourceData, m interface{})
 		})
 		return diags
 	}
+	// the last ancestor is the immediate parent, detects the moves performed outside of terraform
+	if ancestors := res.GetParentOrganizationIds(); len(ancestors) > 0 {
+		d.Set("parent_organization_id", ancestors[len(ancestors)-1])
+	}
+	if err := d.Set("entitlements", flattenBGEntitlements(orginstance)); err != nil {
+		diags := append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to set Business Group entitlements",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
 
 	return diags
 }
@@ -843,6 +921,20 @@ func resourceBGUpdate(ctx context.Context, d *schema.ResourceData, m interface{}
 
 	authctx := getBGAuthCtx(ctx, &pco)
 
+	// the move happens first so that the entitlements are assigned from the new parent's capacity
+	if d.HasChange("parent_organization_id") {
+		parentid := d.Get("parent_organization_id").(string)
+		if err := moveBG(ctx, &pco, orgid, parentid); err != nil {
+			diags := append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Unable to Move Business Group " + orgid + " to " + parentid,
+				Detail:   err.Error(),
+			})
+			return diags
+		}
+		d.Set("last_updated", time.Now().Format(time.RFC850))
+	}
+
 	if d.HasChanges(getBGUpdatableAttributes()...) {
 		body := newBGPutBody(d)
 		_, httpr, err := pco.orgclient.DefaultApi.OrganizationsOrgIdPut(authctx, orgid).BGPutReqBody(*body).Execute()
@@ -865,6 +957,63 @@ func resourceBGUpdate(ctx context.Context, d *schema.ResourceData, m interface{}
 		d.Set("last_updated", time.Now().Format(time.RFC850))
 	}
 
+	if d.HasChanges(getBGAddonEntitlementsAttributes()...) {
+		if err := updateBGAddonEntitlements(ctx, &pco, orgid, d); err != nil {
+			diags := append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Unable to update Business Group entitlements",
+				Detail:   err.Error(),
+			})
+			return diags
+		}
+		d.Set("last_updated", time.Now().Format(time.RFC850))
+	}
+
+	if d.HasChange("properties") {
+		o, n := d.GetChange("properties")
+		old, new := o.(map[string]interface{}), n.(map[string]interface{})
+		if err := modifyOrgProperties(ctx, &pco, orgid, func(current map[string]interface{}) {
+			for k := range old {
+				if _, ok := new[k]; !ok {
+					delete(current, k)
+				}
+			}
+			for k, v := range new {
+				current[k] = v
+			}
+		}); err != nil {
+			diags := append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Unable to update Business Group properties",
+				Detail:   err.Error(),
+			})
+			return diags
+		}
+		d.Set("last_updated", time.Now().Format(time.RFC850))
+	}
+
+	// the platform silently keeps the current owner when the new one cannot own the business group
+	if d.HasChange("owner_id") {
+		ownerid := d.Get("owner_id").(string)
+		res, err := getOrg(authctx, &pco, orgid)
+		if err != nil {
+			diags := append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Unable to Get Business Group " + orgid,
+				Detail:   err.Error(),
+			})
+			return diags
+		}
+		if res.GetOwnerId() != ownerid {
+			diags := append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Unable to transfer the ownership of Business Group " + orgid,
+				Detail:   fmt.Sprintf("the owner is still %s instead of %s after the update", res.GetOwnerId(), ownerid),
+			})
+			return diags
+		}
+	}
+
 	return resourceBGRead(ctx, d, m)
 }
 
@@ -874,8 +1023,57 @@ func resourceBGDelete(ctx context.Context, d *schema.ResourceData, m interface{}
 	pco := m.(ProviderConfOutput)
 	orgid := d.Id()
 
+	if d.Get("deletion_protection").(bool) {
+		diags := append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to Delete Business Group " + orgid,
+			Detail:   "The business group is protected against deletion, set deletion_protection to false and apply before deleting it.",
+		})
+		return diags
+	}
+
 	authctx := getBGAuthCtx(ctx, &pco)
 
+	bg, err := getOrg(authctx, &pco, orgid)
+	if err != nil {
+		diags := append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to check Business Group " + orgid + " inventory",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	blockers := make([]string, 0)
+	for _, e := range bg.GetEnvironments() {
+		envblockers, warnings, err := getENVDeletionBlockers(ctx, &pco, orgid, e.GetId())
+		for _, w := range warnings {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  "Unable to check ENV " + e.GetName() + " inventory",
+				Detail:   w,
+			})
+		}
+		if err != nil {
+			diags := append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Unable to check ENV " + e.GetName() + " inventory",
+				Detail:   err.Error(),
+			})
+			return diags
+		}
+		for _, b := range envblockers {
+			blockers = append(blockers, "environment "+e.GetName()+": "+b)
+		}
+	}
+	if len(blockers) > 0 {
+		diags := append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to Delete Business Group " + orgid,
+			Detail:   "The business group's environments still contain the following resources, remove them before deleting it:\n - " + strings.Join(blockers, "\n - "),
+		})
+		return diags
+	}
+
 	_, httpr, err := pco.orgclient.DefaultApi.OrganizationsOrgIdDelete(authctx, orgid).Execute()
 	if err != nil {
 		var details string
@@ -932,23 +1130,23 @@ func newBGPutBody(d *schema.ResourceData) *org.BGPutReqBody {
  */
 func newEntitlementsFromD(d *schema.ResourceData) *org.EntitlementsCore {
 	loadbalancer := org.NewLoadBalancerWithDefaults()
-	loadbalancer.SetAssigned(int32(d.Get("entitlements_loadbalancer_assigned").(int)))
+	loadbalancer.SetAssigned(int32(getBGEntitlement(d, "entitlements_loadbalancer_assigned").(int)))
 	staticips := org.NewStaticIpsWithDefaults()
-	staticips.SetAssigned(int32(d.Get("entitlements_staticips_assigned").(int)))
+	staticips.SetAssigned(int32(getBGEntitlement(d, "entitlements_staticips_assigned").(int)))
 	vcoresandbox := org.NewVCoresSandboxWithDefaults()
-	vcoresandbox.SetAssigned(float32(d.Get("entitlements_vcoressandbox_assigned").(float64)))
+	vcoresandbox.SetAssigned(float32(getBGEntitlement(d, "entitlements_vcoressandbox_assigned").(float64)))
 	vcoredesign := org.NewVCoresDesignWithDefaults()
-	vcoredesign.SetAssigned(float32(d.Get("entitlements_vcoresdesign_assigned").(float64)))
+	vcoredesign.SetAssigned(float32(getBGEntitlement(d, "entitlements_vcoresdesign_assigned").(float64)))
 	vpns := org.NewVpnsWithDefaults()
-	vpns.SetAssigned(int32(d.Get("entitlements_vpns_assigned").(int)))
+	vpns.SetAssigned(int32(getBGEntitlement(d, "entitlements_vpns_assigned").(int)))
 	vpcs := org.NewVpcsWithDefaults()
-	vpcs.SetAssigned(int32(d.Get("entitlements_vpcs_assigned").(int)))
+	vpcs.SetAssigned(int32(getBGEntitlement(d, "entitlements_vpcs_assigned").(int)))
 	vcoreprod := org.NewVCoresProductionWithDefaults()
-	vcoreprod.SetAssigned(float32(d.Get("entitlements_vcoresproduction_assigned").(float64)))
+	vcoreprod.SetAssigned(float32(getBGEntitlement(d, "entitlements_vcoresproduction_assigned").(float64)))
 	entitlements := org.NewEntitlementsCore(
-		d.Get("entitlements_globaldeployment").(bool),
-		d.Get("entitlements_createenvironments").(bool),
-		d.Get("entitlements_createsuborgs").(bool),
+		getBGEntitlement(d, "entitlements_globaldeployment").(bool),
+		getBGEntitlement(d, "entitlements_createenvironments").(bool),
+		getBGEntitlement(d, "entitlements_createsuborgs").(bool),
 		*loadbalancer,
 		*staticips,
 		*vcoredesign,
@@ -961,6 +1159,71 @@ func newEntitlementsFromD(d *schema.ResourceData) *org.EntitlementsCore {
 	return entitlements
 }
 
+// the writable entitlements not covered by the org client, with their group and field in the organization's entitlements
+var BG_ADDON_ENTITLEMENTS = []struct {
+	Attr  string
+	Group string
+	Field string
+}{
+	{"entitlements_mqmessages_base", "mqMessages", "base"},
+	{"entitlements_mqmessages_addon", "mqMessages", "addOn"},
+	{"entitlements_mqrequests_base", "mqRequests", "base"},
+	{"entitlements_mqrequests_addon", "mqRequests", "addOn"},
+	{"entitlements_mqadvancedfeatures_enabled", "mqAdvancedFeatures", "enabled"},
+	{"entitlements_objectstorerequestunits_base", "objectStoreRequestUnits", "base"},
+	{"entitlements_objectstorerequestunits_addon", "objectStoreRequestUnits", "addOn"},
+	{"entitlements_objectstorekeys_base", "objectStoreKeys", "base"},
+	{"entitlements_objectstorekeys_addon", "objectStoreKeys", "addOn"},
+	{"entitlements_gateways_assigned", "gateways", "assigned"},
+}
+
+// returns the attributes whose change requires the addon entitlements to be updated
+func getBGAddonEntitlementsAttributes() []string {
+	attributes := []string{"entitlements.0.mq", "entitlements.0.object_store", "entitlements.0.api_manager"}
+	for _, e := range BG_ADDON_ENTITLEMENTS {
+		attributes = append(attributes, e.Attr)
+	}
+	return attributes
+}
+
+// returns true if one of the addon entitlements is set in the configuration
+func isBGAddonEntitlementsConfigured(d *schema.ResourceData) bool {
+	for _, e := range BG_ADDON_ENTITLEMENTS {
+		if _, ok := d.GetOk(e.Attr); ok || isBGEntitlementConfigured(d, BG_ENTITLEMENTS_ALIASES[e.Attr]) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+Updates the entitlements not covered by the org client (Anypoint MQ, Object Store and API gateways).
+The entitlements are sent as a whole, the ones managed by the org client are sent along with their configured value.
+*/
+func updateBGAddonEntitlements(ctx context.Context, pco *ProviderConfOutput, orgid string, d *schema.ResourceData) error {
+	b, err := json.Marshal(newEntitlementsFromD(d))
+	if err != nil {
+		return err
+	}
+	entitlements := make(map[string]interface{})
+	if err := json.Unmarshal(b, &entitlements); err != nil {
+		return err
+	}
+	for _, e := range BG_ADDON_ENTITLEMENTS {
+		group, ok := entitlements[e.Group].(map[string]interface{})
+		if !ok {
+			group = make(map[string]interface{})
+			entitlements[e.Group] = group
+		}
+		group[e.Field] = getBGEntitlement(d, e.Attr)
+	}
+	body := map[string]interface{}{
+		"entitlements": entitlements,
+	}
+	_, err = doAnypointRequest(ctx, pco, http.MethodPut, "/accounts/api/organizations/"+orgid, body, nil)
+	return err
+}
+
 /*
  * Returns authentication context (includes authorization header)
  */
@@ -968,3 +1231,624 @@ func getBGAuthCtx(ctx context.Context, pco *ProviderConfOutput) context.Context
 	tmp := context.WithValue(ctx, org.ContextAccessToken, pco.access_token)
 	return context.WithValue(tmp, org.ContextServerIndex, pco.server_index)
 }
+
+// the entitlements assigned by a parent organization to its business groups
+var BG_CAPACITY_ENTITLEMENTS = []struct {
+	Key   string
+	Label string
+}{
+	{"vcoresproduction", "production vCores"},
+	{"vcoressandbox", "sandbox vCores"},
+	{"vcoresdesign", "design vCores"},
+	{"staticips", "static IPs"},
+	{"vpcs", "VPCs"},
+	{"vpns", "VPNs"},
+	{"loadbalancer", "load balancers"},
+}
+
+/*
+Validates at plan time the moves, the ownership transfers and the entitlements of the business group.
+*/
+func resourceBGCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	pco := m.(ProviderConfOutput)
+	moved := d.Id() != "" && d.HasChange("parent_organization_id")
+	if moved && d.NewValueKnown("parent_organization_id") {
+		if err := validateBGMove(ctx, &pco, d.Id(), d.Get("parent_organization_id").(string)); err != nil {
+			return err
+		}
+	}
+	if d.Id() != "" && d.HasChange("owner_id") && d.NewValueKnown("owner_id") {
+		if err := validateBGOwner(ctx, &pco, d.Id(), d.Get("owner_id").(string)); err != nil {
+			return err
+		}
+	}
+	return validateBGEntitlementsCapacity(ctx, d, &pco, moved)
+}
+
+/*
+Validates at plan time that the entitlements assigned to the business group fit in its parent's capacity.
+The entitlements assigned to the sibling business groups, taken from the parent's reassigned capacity, are compared to the parent's assigned capacity,
+only the entitlements increased by the plan are checked so that existing over-allocations do not block decreases.
+All the entitlements are checked when the business group is created or moved to a new parent.
+*/
+func validateBGEntitlementsCapacity(ctx context.Context, d *schema.ResourceDiff, pco *ProviderConfOutput, moved bool) error {
+	if d.Get("skip_entitlements_validation").(bool) || !d.NewValueKnown("parent_organization_id") {
+		return nil
+	}
+	increased := make([]int, 0)
+	requested := make(map[string]float64)
+	current := make(map[string]float64)
+	for i, e := range BG_CAPACITY_ENTITLEMENTS {
+		attr := getBGEntitlementDiffAttr(d, "entitlements_"+e.Key+"_assigned")
+		if !d.NewValueKnown(attr) {
+			continue
+		}
+		o, n := d.GetChange(attr)
+		requested[e.Key] = entitlementValue2Float64(n)
+		// the current assignment only counts in the parent's reassigned capacity if the business group already is its child
+		if d.Id() != "" && !moved {
+			current[e.Key] = entitlementValue2Float64(o)
+		}
+		if requested[e.Key] > current[e.Key] {
+			increased = append(increased, i)
+		}
+	}
+	if len(increased) == 0 {
+		return nil
+	}
+
+	authctx := getBGAuthCtx(ctx, pco)
+	parentid := d.Get("parent_organization_id").(string)
+	parent, err := getOrg(authctx, pco, parentid)
+	if err != nil {
+		return err
+	}
+	parentdata := flattenBGData(parent)
+
+	shortfalls := make([]string, 0)
+	for _, i := range increased {
+		e := BG_CAPACITY_ENTITLEMENTS[i]
+		capacity := entitlementValue2Float64(parentdata["entitlements_"+e.Key+"_assigned"])
+		// the parent's reassigned capacity is the capacity assigned to its direct children
+		siblings := entitlementValue2Float64(parentdata["entitlements_"+e.Key+"_reassigned"]) - current[e.Key]
+		if shortfall := siblings + requested[e.Key] - capacity; shortfall > 1e-6 {
+			shortfalls = append(shortfalls, fmt.Sprintf(
+				"  - %s: requested %s, assigned to sibling business groups %s, parent capacity %s, shortfall %s",
+				e.Label, formatEntitlementValue(requested[e.Key]), formatEntitlementValue(siblings),
+				formatEntitlementValue(capacity), formatEntitlementValue(shortfall),
+			))
+		}
+	}
+	if len(shortfalls) > 0 {
+		return fmt.Errorf("business group %q over-allocates the entitlements of its parent organization %s:\n%s", d.Get("name").(string), parentid, strings.Join(shortfalls, "\n"))
+	}
+	return nil
+}
+
+/*
+Validates that the business group can be moved under the given parent:
+the master organization cannot be moved, the parent must be part of the same master organization and cannot be one of the business group's descendants.
+*/
+func validateBGMove(ctx context.Context, pco *ProviderConfOutput, orgid string, parentid string) error {
+	if parentid == orgid {
+		return fmt.Errorf("business group %s cannot be moved under itself", orgid)
+	}
+	authctx := getBGAuthCtx(ctx, pco)
+	bg, err := getOrg(authctx, pco, orgid)
+	if err != nil {
+		return err
+	}
+	if bg.GetIsMaster() {
+		return fmt.Errorf("business group %s is a master organization and cannot be moved", orgid)
+	}
+	parent, err := getOrg(authctx, pco, parentid)
+	if err != nil {
+		return err
+	}
+	if StringInSlice(parent.GetParentOrganizationIds(), orgid, false) {
+		return fmt.Errorf("business group %s cannot be moved under its descendant %s", orgid, parentid)
+	}
+	if getBGMasterId(bg) != getBGMasterId(parent) {
+		return fmt.Errorf("business group %s can only be moved under a business group of its master organization %s", orgid, getBGMasterId(bg))
+	}
+	return nil
+}
+
+// validates that the new owner is an enabled user of the business group's master organization
+func validateBGOwner(ctx context.Context, pco *ProviderConfOutput, orgid string, ownerid string) error {
+	bg, err := getOrg(getBGAuthCtx(ctx, pco), pco, orgid)
+	if err != nil {
+		return err
+	}
+	masterid := getBGMasterId(bg)
+	user, httpr, err := pco.userclient.DefaultApi.OrganizationsOrgIdUsersUserIdGet(getUserAuthCtx(ctx, pco), masterid, ownerid).Execute()
+	if err != nil {
+		if httpr != nil && httpr.StatusCode == http.StatusNotFound {
+			return fmt.Errorf("the new owner %s of business group %s is not a user of the master organization %s", ownerid, orgid, masterid)
+		}
+		var details string
+		if httpr != nil {
+			b, _ := ioutil.ReadAll(httpr.Body)
+			details = string(b)
+		} else {
+			details = err.Error()
+		}
+		return fmt.Errorf("unable to get user %s\n details: %s", ownerid, details)
+	}
+	httpr.Body.Close()
+	if enabled, ok := user.GetEnabledOk(); ok && !*enabled {
+		return fmt.Errorf("the new owner %s of business group %s is a disabled user", ownerid, orgid)
+	}
+	return nil
+}
+
+// returns the id of the master organization of the given business group
+func getBGMasterId(bg *org.MasterBGDetail) string {
+	if ancestors := bg.GetParentOrganizationIds(); !bg.GetIsMaster() && len(ancestors) > 0 {
+		return ancestors[0]
+	}
+	return bg.GetId()
+}
+
+// moves the business group and its descendants under the given parent and verifies the move
+func moveBG(ctx context.Context, pco *ProviderConfOutput, orgid string, parentid string) error {
+	body := map[string]interface{}{
+		"parentOrganizationId": parentid,
+	}
+	if _, err := doAnypointRequest(ctx, pco, http.MethodPut, "/accounts/api/organizations/"+orgid, body, nil); err != nil {
+		return err
+	}
+	// the platform may accept the request without moving the business group
+	res, err := getOrg(getBGAuthCtx(ctx, pco), pco, orgid)
+	if err != nil {
+		return err
+	}
+	current := ""
+	if ancestors := res.GetParentOrganizationIds(); len(ancestors) > 0 {
+		current = ancestors[len(ancestors)-1]
+	}
+	if current != parentid {
+		return fmt.Errorf("the parent is still %s instead of %s after the update", current, parentid)
+	}
+	return nil
+}
+
+// returns the current value of the given managed properties, the properties removed from the organization are left out
+func flattenBGManagedProperties(props map[string]interface{}, managed map[string]interface{}) map[string]interface{} {
+	res := make(map[string]interface{})
+	for k := range managed {
+		if v, ok := props[k]; ok {
+			res[k] = orgPropertyValue2String(v)
+		}
+	}
+	return res
+}
+
+// the organization's properties are free form, the values which are not strings are returned as JSON
+func orgPropertyValue2String(v interface{}) string {
+	if str, ok := v.(string); ok {
+		return str
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// the schema of the business group before the properties became configurable
+func resourceBGV0() *schema.Resource {
+	r := resourceBGV1()
+	r.Schema["properties"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Computed: true,
+	}
+	return &schema.Resource{
+		Schema: r.Schema,
+	}
+}
+
+// the properties used to be a read-only string, they are dropped and only the configured keys are read again
+func resourceBGStateUpgradeV0(ctx context.Context, rawState map[string]interface{}, meta interface{}) (map[string]interface{}, error) {
+	delete(rawState, "properties")
+	return rawState, nil
+}
+
+func entitlementValue2Float64(v interface{}) float64 {
+	switch n := v.(type) {
+	case float32:
+		// goes through the shortest decimal representation to avoid float32 artifacts (0.1 => 0.100000001)
+		f, _ := strconv.ParseFloat(strconv.FormatFloat(float64(n), 'f', -1, 32), 64)
+		return f
+	case float64:
+		return n
+	case int32:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case int:
+		return float64(n)
+	}
+	return 0
+}
+
+func formatEntitlementValue(v float64) string {
+	return strconv.FormatFloat(math.Round(v*1000)/1000, 'f', -1, 64)
+}
+
+// the writable entitlements flat attributes and their counterparts in the entitlements block
+var BG_ENTITLEMENTS_ALIASES = map[string]string{
+	"entitlements_createenvironments":            "entitlements.0.create_environments",
+	"entitlements_globaldeployment":              "entitlements.0.global_deployment",
+	"entitlements_createsuborgs":                 "entitlements.0.create_sub_orgs",
+	"entitlements_vcoresproduction_assigned":     "entitlements.0.vcores.0.production_assigned",
+	"entitlements_vcoressandbox_assigned":        "entitlements.0.vcores.0.sandbox_assigned",
+	"entitlements_vcoresdesign_assigned":         "entitlements.0.vcores.0.design_assigned",
+	"entitlements_staticips_assigned":            "entitlements.0.network.0.static_ips_assigned",
+	"entitlements_vpcs_assigned":                 "entitlements.0.network.0.vpcs_assigned",
+	"entitlements_vpns_assigned":                 "entitlements.0.network.0.vpns_assigned",
+	"entitlements_loadbalancer_assigned":         "entitlements.0.network.0.load_balancers_assigned",
+	"entitlements_mqmessages_base":               "entitlements.0.mq.0.messages_base",
+	"entitlements_mqmessages_addon":              "entitlements.0.mq.0.messages_addon",
+	"entitlements_mqrequests_base":               "entitlements.0.mq.0.requests_base",
+	"entitlements_mqrequests_addon":              "entitlements.0.mq.0.requests_addon",
+	"entitlements_mqadvancedfeatures_enabled":    "entitlements.0.mq.0.advanced_features_enabled",
+	"entitlements_objectstorerequestunits_base":  "entitlements.0.object_store.0.request_units_base",
+	"entitlements_objectstorerequestunits_addon": "entitlements.0.object_store.0.request_units_addon",
+	"entitlements_objectstorekeys_base":          "entitlements.0.object_store.0.keys_base",
+	"entitlements_objectstorekeys_addon":         "entitlements.0.object_store.0.keys_addon",
+	"entitlements_gateways_assigned":             "entitlements.0.api_manager.0.gateways_assigned",
+}
+
+/*
+Returns the value of the given writable entitlement.
+The value of the entitlements block is used when set in the configuration, the deprecated flat attribute otherwise.
+*/
+func getBGEntitlement(d *schema.ResourceData, flat string) interface{} {
+	alias := BG_ENTITLEMENTS_ALIASES[flat]
+	if isBGEntitlementConfigured(d, alias) {
+		return d.Get(alias)
+	}
+	return d.Get(flat)
+}
+
+// returns true if the given path (e.g. entitlements.0.vcores.0.production_assigned) is set in the configuration
+func isBGEntitlementConfigured(d *schema.ResourceData, path string) bool {
+	v := d.GetRawConfig()
+	for _, step := range strings.Split(path, ".") {
+		if v.IsNull() || !v.IsKnown() {
+			return false
+		}
+		if step == "0" {
+			if v.LengthInt() == 0 {
+				return false
+			}
+			v = v.AsValueSlice()[0]
+		} else {
+			v = v.GetAttr(step)
+		}
+	}
+	return !v.IsNull()
+}
+
+/*
+Returns the attribute holding the planned value of the given writable entitlement.
+Both the deprecated flat attribute and the entitlements block are kept in sync by the read,
+so the flat attribute is only used when its value is changed by the configuration.
+*/
+func getBGEntitlementDiffAttr(d *schema.ResourceDiff, flat string) string {
+	if d.HasChange(flat) {
+		return flat
+	}
+	return BG_ENTITLEMENTS_ALIASES[flat]
+}
+
+func resourceBGEntitlementsSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		Computed:    true,
+		MaxItems:    1,
+		Description: "The entitlements of this organization. The configurable values left unset keep their current value, the others are read-only.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"create_environments": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Computed:    true,
+					Description: "Whether this organization can have additional environments.",
+				},
+				"global_deployment": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Computed:    true,
+					Description: "Whether this organization can have global deployments.",
+				},
+				"create_sub_orgs": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Computed:    true,
+					Description: "Whether this organization can create sub organizations (descendants).",
+				},
+				"vcores": {
+					Type:        schema.TypeList,
+					Optional:    true,
+					Computed:    true,
+					MaxItems:    1,
+					Description: "The vCores assigned to this organization.",
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"production_assigned": {
+								Type:             schema.TypeFloat,
+								Optional:         true,
+								Computed:         true,
+								Description:      "The number of production vCores assigned to this organization.",
+								ValidateDiagFunc: validation.ToDiagFunc(validation.FloatAtLeast(0)),
+							},
+							"production_reassigned": {
+								Type:        schema.TypeFloat,
+								Computed:    true,
+								Description: "The number of production vCores reassigned by this organization to its children.",
+							},
+							"sandbox_assigned": {
+								Type:             schema.TypeFloat,
+								Optional:         true,
+								Computed:         true,
+								Description:      "The number of sandbox vCores assigned to this organization.",
+								ValidateDiagFunc: validation.ToDiagFunc(validation.FloatAtLeast(0)),
+							},
+							"sandbox_reassigned": {
+								Type:        schema.TypeFloat,
+								Computed:    true,
+								Description: "The number of sandbox vCores reassigned by this organization to its children.",
+							},
+							"design_assigned": {
+								Type:             schema.TypeFloat,
+								Optional:         true,
+								Computed:         true,
+								Description:      "The number of design vCores assigned to this organization.",
+								ValidateDiagFunc: validation.ToDiagFunc(validation.FloatAtLeast(0)),
+							},
+							"design_reassigned": {
+								Type:        schema.TypeFloat,
+								Computed:    true,
+								Description: "The number of design vCores reassigned by this organization to its children.",
+							},
+						},
+					},
+				},
+				"network": {
+					Type:        schema.TypeList,
+					Optional:    true,
+					Computed:    true,
+					MaxItems:    1,
+					Description: "The network resources assigned to this organization.",
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"static_ips_assigned": {
+								Type:             schema.TypeInt,
+								Optional:         true,
+								Computed:         true,
+								Description:      "The number of static IPs assigned to this organization.",
+								ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(0)),
+							},
+							"static_ips_reassigned": {
+								Type:        schema.TypeInt,
+								Computed:    true,
+								Description: "The number of static IPs reassigned by this organization to its children.",
+							},
+							"vpcs_assigned": {
+								Type:             schema.TypeInt,
+								Optional:         true,
+								Computed:         true,
+								Description:      "The number of VPCs assigned to this organization.",
+								ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(0)),
+							},
+							"vpcs_reassigned": {
+								Type:        schema.TypeInt,
+								Computed:    true,
+								Description: "The number of VPCs reassigned by this organization to its children.",
+							},
+							"vpns_assigned": {
+								Type:             schema.TypeInt,
+								Optional:         true,
+								Computed:         true,
+								Description:      "The number of VPNs assigned to this organization.",
+								ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(0)),
+							},
+							"vpns_reassigned": {
+								Type:        schema.TypeInt,
+								Computed:    true,
+								Description: "The number of VPNs reassigned by this organization to its children.",
+							},
+							"load_balancers_assigned": {
+								Type:             schema.TypeInt,
+								Optional:         true,
+								Computed:         true,
+								Description:      "The number of dedicated load balancers (DLB) assigned to this organization.",
+								ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(0)),
+							},
+							"load_balancers_reassigned": {
+								Type:        schema.TypeInt,
+								Computed:    true,
+								Description: "The number of dedicated load balancers (DLB) reassigned by this organization to its children.",
+							},
+						},
+					},
+				},
+				"mq": {
+					Type:        schema.TypeList,
+					Optional:    true,
+					Computed:    true,
+					MaxItems:    1,
+					Description: "The Anypoint MQ entitlements of this organization.",
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"messages_base": {
+								Type:             schema.TypeInt,
+								Optional:         true,
+								Computed:         true,
+								Description:      "The base number of MQ messages.",
+								ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(0)),
+							},
+							"messages_addon": {
+								Type:             schema.TypeInt,
+								Optional:         true,
+								Computed:         true,
+								Description:      "The add-on number of MQ messages.",
+								ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(0)),
+							},
+							"requests_base": {
+								Type:             schema.TypeInt,
+								Optional:         true,
+								Computed:         true,
+								Description:      "The base number of MQ API requests.",
+								ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(0)),
+							},
+							"requests_addon": {
+								Type:             schema.TypeInt,
+								Optional:         true,
+								Computed:         true,
+								Description:      "The add-on number of MQ API requests.",
+								ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(0)),
+							},
+							"advanced_features_enabled": {
+								Type:        schema.TypeBool,
+								Optional:    true,
+								Computed:    true,
+								Description: "Whether MQ advanced features are enabled.",
+							},
+						},
+					},
+				},
+				"object_store": {
+					Type:        schema.TypeList,
+					Optional:    true,
+					Computed:    true,
+					MaxItems:    1,
+					Description: "The Object Store entitlements of this organization.",
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"request_units_base": {
+								Type:             schema.TypeInt,
+								Optional:         true,
+								Computed:         true,
+								Description:      "The base number of Object Store request units.",
+								ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(0)),
+							},
+							"request_units_addon": {
+								Type:             schema.TypeInt,
+								Optional:         true,
+								Computed:         true,
+								Description:      "The add-on number of Object Store request units.",
+								ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(0)),
+							},
+							"keys_base": {
+								Type:             schema.TypeInt,
+								Optional:         true,
+								Computed:         true,
+								Description:      "The base number of Object Store keys.",
+								ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(0)),
+							},
+							"keys_addon": {
+								Type:             schema.TypeInt,
+								Optional:         true,
+								Computed:         true,
+								Description:      "The add-on number of Object Store keys.",
+								ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(0)),
+							},
+						},
+					},
+				},
+				"api_manager": {
+					Type:        schema.TypeList,
+					Optional:    true,
+					Computed:    true,
+					MaxItems:    1,
+					Description: "The API Manager entitlements of this organization, only the number of gateways is configurable.",
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"apis_enabled": {
+								Type:        schema.TypeBool,
+								Computed:    true,
+								Description: "Whether API Manager is enabled.",
+							},
+							"api_monitoring_schedules": {
+								Type:        schema.TypeInt,
+								Computed:    true,
+								Description: "The number of API monitoring schedules.",
+							},
+							"api_community_manager_enabled": {
+								Type:        schema.TypeBool,
+								Computed:    true,
+								Description: "Whether API Community Manager is enabled.",
+							},
+							"api_query_enabled": {
+								Type:        schema.TypeBool,
+								Computed:    true,
+								Description: "Whether API Query is enabled.",
+							},
+							"gateways_assigned": {
+								Type:             schema.TypeInt,
+								Optional:         true,
+								Computed:         true,
+								Description:      "The number of API gateways assigned.",
+								ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(0)),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// builds the entitlements block from the flattened business group
+func flattenBGEntitlements(bg map[string]interface{}) []interface{} {
+	vcores := map[string]interface{}{
+		"production_assigned":   entitlementValue2Float64(bg["entitlements_vcoresproduction_assigned"]),
+		"production_reassigned": entitlementValue2Float64(bg["entitlements_vcoresproduction_reassigned"]),
+		"sandbox_assigned":      entitlementValue2Float64(bg["entitlements_vcoressandbox_assigned"]),
+		"sandbox_reassigned":    entitlementValue2Float64(bg["entitlements_vcoressandbox_reassigned"]),
+		"design_assigned":       entitlementValue2Float64(bg["entitlements_vcoresdesign_assigned"]),
+		"design_reassigned":     entitlementValue2Float64(bg["entitlements_vcoresdesign_reassigned"]),
+	}
+	network := map[string]interface{}{
+		"static_ips_assigned":       bg["entitlements_staticips_assigned"],
+		"static_ips_reassigned":     bg["entitlements_staticips_reassigned"],
+		"vpcs_assigned":             bg["entitlements_vpcs_assigned"],
+		"vpcs_reassigned":           bg["entitlements_vpcs_reassigned"],
+		"vpns_assigned":             bg["entitlements_vpns_assigned"],
+		"vpns_reassigned":           bg["entitlements_vpns_reassigned"],
+		"load_balancers_assigned":   bg["entitlements_loadbalancer_assigned"],
+		"load_balancers_reassigned": bg["entitlements_loadbalancer_reassigned"],
+	}
+	mq := map[string]interface{}{
+		"messages_base":             bg["entitlements_mqmessages_base"],
+		"messages_addon":            bg["entitlements_mqmessages_addon"],
+		"requests_base":             bg["entitlements_mqrequests_base"],
+		"requests_addon":            bg["entitlements_mqrequests_addon"],
+		"advanced_features_enabled": bg["entitlements_mqadvancedfeatures_enabled"],
+	}
+	objectstore := map[string]interface{}{
+		"request_units_base":  bg["entitlements_objectstorerequestunits_base"],
+		"request_units_addon": bg["entitlements_objectstorerequestunits_addon"],
+		"keys_base":           bg["entitlements_objectstorekeys_base"],
+		"keys_addon":          bg["entitlements_objectstorekeys_addon"],
+	}
+	apimanager := map[string]interface{}{
+		"apis_enabled":                  bg["entitlements_apis_enabled"],
+		"api_monitoring_schedules":      bg["entitlements_apimonitoring_schedules"],
+		"api_community_manager_enabled": bg["entitlements_apicommunitymanager_enabled"],
+		"api_query_enabled":             bg["entitlements_apiquery_enabled"],
+		"gateways_assigned":             bg["entitlements_gateways_assigned"],
+	}
+	entitlements := map[string]interface{}{
+		"create_environments": bg["entitlements_createenvironments"],
+		"global_deployment":   bg["entitlements_globaldeployment"],
+		"create_sub_orgs":     bg["entitlements_createsuborgs"],
+		"vcores":              []interface{}{vcores},
+		"network":             []interface{}{network},
+		"mq":                  []interface{}{mq},
+		"object_store":        []interface{}{objectstore},
+		"api_manager":         []interface{}{apimanager},
+	}
+	return []interface{}{entitlements}
+}
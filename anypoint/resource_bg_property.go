@@ -0,0 +1,233 @@
+package anypoint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	org "github.com/mulesoft-anypoint/anypoint-client-go/org"
+)
+
+// serializes the read-modify-write cycles performed on the same organization's properties by this provider
+var orgPropertiesLocks sync.Map
+
+func resourceBGProperty() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceBGPropertyCreate,
+		ReadContext:   resourceBGPropertyRead,
+		UpdateContext: resourceBGPropertyUpdate,
+		DeleteContext: resourceBGPropertyDelete,
+		Description: `
+		Manages a single property of a business group (org), the other properties of the business group are left untouched.
+		Do not manage the same key in the ` + "`" + `properties` + "`" + ` of ` + "`" + `anypoint_bg` + "`" + `, both resources would keep overwriting each other's value.
+		`,
+		Schema: map[string]*schema.Schema{
+			"last_updated": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "The last time this resource has been updated locally.",
+			},
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The unique id of this property composed of {org_id}/{key}.",
+			},
+			"org_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The business group id.",
+			},
+			"key": {
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				Description:      "The property key.",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsNotWhiteSpace),
+			},
+			"value": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The property value. The values which are not strings are read as JSON.",
+			},
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+	}
+}
+
+func resourceBGPropertyCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	pco := m.(ProviderConfOutput)
+	orgid := d.Get("org_id").(string)
+	key := d.Get("key").(string)
+	value := d.Get("value").(string)
+
+	err := modifyOrgProperties(ctx, &pco, orgid, func(props map[string]interface{}) {
+		props[key] = value
+	})
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to set property " + key + " of Business Group " + orgid,
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	d.SetId(ComposeResourceId([]string{orgid, key}))
+
+	return resourceBGPropertyRead(ctx, d, m)
+}
+
+func resourceBGPropertyRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	pco := m.(ProviderConfOutput)
+	orgid, key, err := decomposeBGPropertyId(d)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Invalid Business Group property id",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	res, err := getOrg(getBGAuthCtx(ctx, &pco), &pco, orgid)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to get property " + key + " of Business Group " + orgid,
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	props := getOrgProperties(res)
+	value, ok := props[key]
+	if !ok {
+		// the property has been removed outside of terraform
+		d.SetId("")
+		return diags
+	}
+
+	// setting resource id components for import purposes
+	d.Set("org_id", orgid)
+	d.Set("key", key)
+	d.Set("value", orgPropertyValue2String(value))
+
+	return diags
+}
+
+func resourceBGPropertyUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	pco := m.(ProviderConfOutput)
+	orgid, key, err := decomposeBGPropertyId(d)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Invalid Business Group property id",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	if d.HasChange("value") {
+		value := d.Get("value").(string)
+		err := modifyOrgProperties(ctx, &pco, orgid, func(props map[string]interface{}) {
+			props[key] = value
+		})
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Unable to update property " + key + " of Business Group " + orgid,
+				Detail:   err.Error(),
+			})
+			return diags
+		}
+		d.Set("last_updated", time.Now().Format(time.RFC850))
+	}
+
+	return resourceBGPropertyRead(ctx, d, m)
+}
+
+func resourceBGPropertyDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	pco := m.(ProviderConfOutput)
+	orgid, key, err := decomposeBGPropertyId(d)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Invalid Business Group property id",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	err = modifyOrgProperties(ctx, &pco, orgid, func(props map[string]interface{}) {
+		delete(props, key)
+	})
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to delete property " + key + " of Business Group " + orgid,
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	// d.SetId("") is automatically called assuming delete returns no errors, but
+	// it is added here for explicitness.
+	d.SetId("")
+
+	return diags
+}
+
+// the key may contain the id separator, only the first one separates the org id from the key
+func decomposeBGPropertyId(d *schema.ResourceData) (string, string, error) {
+	s := strings.SplitN(d.Id(), COMPOSITE_ID_SEPARATOR, 2)
+	if len(s) != 2 || s[0] == "" || s[1] == "" {
+		return "", "", fmt.Errorf("invalid business group property id %q, expected {org_id}%s{key}", d.Id(), COMPOSITE_ID_SEPARATOR)
+	}
+	return s[0], s[1], nil
+}
+
+/*
+Performs a read-modify-write of the organization's properties.
+The properties are replaced as a whole by the platform, so the other properties are read and written back.
+*/
+func modifyOrgProperties(ctx context.Context, pco *ProviderConfOutput, orgid string, modify func(map[string]interface{})) error {
+	l, _ := orgPropertiesLocks.LoadOrStore(orgid, &sync.Mutex{})
+	lock := l.(*sync.Mutex)
+	lock.Lock()
+	defer lock.Unlock()
+
+	res, err := getOrg(getBGAuthCtx(ctx, pco), pco, orgid)
+	if err != nil {
+		return err
+	}
+	props := getOrgProperties(res)
+	modify(props)
+	body := map[string]interface{}{
+		"properties": props,
+	}
+	_, err = doAnypointRequest(ctx, pco, http.MethodPut, "/accounts/api/organizations/"+orgid, body, nil)
+	return err
+}
+
+// returns the organization's properties as a generic map
+func getOrgProperties(res *org.MasterBGDetail) map[string]interface{} {
+	props := make(map[string]interface{})
+	if val, ok := res.GetPropertiesOk(); ok {
+		b, _ := json.Marshal(val)
+		json.Unmarshal(b, &props)
+	}
+	return props
+}
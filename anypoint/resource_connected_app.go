@@ -3,9 +3,12 @@ package anypoint
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io/ioutil"
+	"net/http"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -20,8 +23,11 @@ func resourceConnectedApp() *schema.Resource {
 		ReadContext:   resourceConnectedAppRead,
 		UpdateContext: resourceConnectedAppUpdate,
 		DeleteContext: resourceConnectedAppDelete,
+		CustomizeDiff: resourceConnectedAppCustomizeDiff,
 		Description: `
 		Creates a ` + "`" + `connected app` + "`" + `.
+		The client secret can be rotated using ` + "`" + `rotation_trigger` + "`" + ` or periodically using ` + "`" + `rotate_after_days` + "`" + `.
+		The secret is regenerated by the platform, the previous secret remains available in ` + "`" + `previous_secret` + "`" + ` during ` + "`" + `rotation_grace_period_days` + "`" + `.
 		`,
 		Schema: map[string]*schema.Schema{
 			"id": {
@@ -38,7 +44,7 @@ func resourceConnectedApp() *schema.Resource {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Sensitive:   true,
-				Description: "The secret of the connected app.",
+				Description: "The secret of the connected app. Always holds the current secret, cannot be set along with secret rotation.",
 				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
 					if new == "" {
 						return true
@@ -47,6 +53,43 @@ func resourceConnectedApp() *schema.Resource {
 					}
 				},
 			},
+			"rotation_trigger": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"secret"},
+				Description:   "Any change of this value regenerates the secret of the connected app.",
+			},
+			"rotate_after_days": {
+				Type:             schema.TypeInt,
+				Optional:         true,
+				Default:          0,
+				ConflictsWith:    []string{"secret"},
+				Description:      "The number of days after which the secret is regenerated on the next apply. 0 disables the periodic rotation.",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(0)),
+			},
+			"rotation_grace_period_days": {
+				Type:             schema.TypeInt,
+				Optional:         true,
+				Default:          7,
+				Description:      "The number of days the previous secret remains visible in previous_secret after a rotation.",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(0)),
+			},
+			"secret_created_at": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The time the current secret was generated (RFC3339 format). When unknown, e.g. after an import, it is initialised at the first read.",
+			},
+			"previous_secret": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "The secret in use before the last rotation, available during the grace period.",
+			},
+			"previous_secret_expires_at": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The end of the previous secret's grace period (RFC3339 format).",
+			},
 			"user_id": {
 				Type:        schema.TypeString,
 				Computed:    true,
@@ -88,13 +131,17 @@ func resourceConnectedApp() *schema.Resource {
 				},
 			},
 			"scope": {
-				Description: "The scopes this connected app has authorization to work on",
+				Description: "The scopes this connected app has authorization to work on. Cannot be set when scopes_managed_separately is true.",
 				Type:        schema.TypeList,
 				Optional:    true,
 				DefaultFunc: func() (interface{}, error) {
 					return make([]interface{}, 0), nil
 				},
 				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+					// the scopes are granted using anypoint_connected_app_scope
+					if d.Get("scopes_managed_separately").(bool) {
+						return true
+					}
 					return equalsConnectedAppScopes(d.GetChange("scope"))
 				},
 				Elem: &schema.Resource{
@@ -117,6 +164,18 @@ func resourceConnectedApp() *schema.Resource {
 					},
 				},
 			},
+			"scopes_managed_separately": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "If true, the scopes of this connected app are granted using anypoint_connected_app_scope and are ignored by this resource.",
+			},
+			"validate_scopes": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Whether the scopes are validated at plan time against the catalog of scopes known by this provider (see data source anypoint_connected_app_scopes). Scopes that are not known at plan time are only checked by the platform. Set it to false for scopes the catalog doesn't list yet.",
+			},
 			"public_keys": {
 				Type:     schema.TypeList,
 				Optional: true,
@@ -126,11 +185,25 @@ func resourceConnectedApp() *schema.Resource {
 				Elem: &schema.Schema{
 					Type: schema.TypeString,
 				},
+				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+					// the keys are registered using anypoint_connected_app_key
+					if d.Get("public_keys_managed_separately").(bool) {
+						return true
+					}
+					return equalStrList(d.GetChange("public_keys"))
+				},
 				Description: `
 				Application public key (PEM format). Used to validate JWT authorization grants.
 				Required when grant type jwt-bearer is selected.
+				Cannot be set when public_keys_managed_separately is true.
 				`,
 			},
+			"public_keys_managed_separately": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "If true, the public keys of this connected app are registered using anypoint_connected_app_key and are ignored by this resource.",
+			},
 			"client_uri": {
 				Type:     schema.TypeString,
 				Optional: true,
@@ -196,12 +269,13 @@ func resourceConnectedAppCreate(ctx context.Context, d *schema.ResourceData, m i
 	defer httpr.Body.Close()
 
 	d.SetId(res.GetClientId())
+	d.Set("secret_created_at", time.Now().UTC().Format(time.RFC3339))
 
 	// Is it a "on its own behalf" connected apps?
 	if grant_types, ok := body.GetGrantTypesOk(); ok && StringInSlice(*grant_types, "client_credentials", true) {
 
 		// Are there scopes to be saved?
-		if scopes := d.Get("scope"); scopes != nil && len(scopes.([]interface{})) > 0 {
+		if scopes := d.Get("scope"); scopes != nil && len(scopes.([]interface{})) > 0 && !d.Get("scopes_managed_separately").(bool) {
 
 			// Save the connected app scopes
 			if error := replaceConnectedAppScopes(authctx, d, m); error != nil {
@@ -273,6 +347,15 @@ func resourceConnectedAppRead(ctx context.Context, d *schema.ResourceData, m int
 		})
 		return diags
 	}
+	// the age of a secret not set by this provider is counted from its first read
+	if d.Get("secret_created_at").(string) == "" {
+		d.Set("secret_created_at", time.Now().UTC().Format(time.RFC3339))
+	}
+	// the previous secret is only kept during the grace period
+	if expiresat, err := time.Parse(time.RFC3339, d.Get("previous_secret_expires_at").(string)); err == nil && time.Now().After(expiresat) {
+		d.Set("previous_secret", "")
+		d.Set("previous_secret_expires_at", "")
+	}
 
 	return diags
 }
@@ -284,6 +367,9 @@ func resourceConnectedAppUpdate(ctx context.Context, d *schema.ResourceData, m i
 
 	authctx := getConnectedAppAuthCtx(ctx, &pco)
 
+	oldcreatedat, _ := d.GetChange("secret_created_at")
+	rotate := d.HasChange("rotation_trigger") || isConnectedAppSecretRotationDue(oldcreatedat.(string), d.Get("rotate_after_days").(int))
+
 	if d.HasChanges(getConnectedAppAttributes()...) {
 		body := newConnectedAppPatchBody(d)
 		//request env creation
@@ -310,7 +396,7 @@ func resourceConnectedAppUpdate(ctx context.Context, d *schema.ResourceData, m i
 		if grant_types, ok := body.GetGrantTypesOk(); ok && StringInSlice(*grant_types, "client_credentials", true) {
 
 			// Are there scopes to be saved?
-			if scopes := d.Get("scope"); scopes != nil && len(scopes.([]interface{})) > 0 {
+			if scopes := d.Get("scope"); scopes != nil && len(scopes.([]interface{})) > 0 && !d.Get("scopes_managed_separately").(bool) {
 
 				// Save the connected app scopes
 				if error := replaceConnectedAppScopes(authctx, d, m); error != nil {
@@ -325,6 +411,24 @@ func resourceConnectedAppUpdate(ctx context.Context, d *schema.ResourceData, m i
 		}
 	}
 
+	if rotate {
+		previoussecret := d.Get("secret").(string)
+		secret, err := regenerateConnectedAppSecret(ctx, &pco, connappid)
+		if err != nil {
+			diags := append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Unable to regenerate Connected App secret",
+				Detail:   err.Error(),
+			})
+			return diags
+		}
+		now := time.Now().UTC()
+		d.Set("secret", secret)
+		d.Set("secret_created_at", now.Format(time.RFC3339))
+		d.Set("previous_secret", previoussecret)
+		d.Set("previous_secret_expires_at", now.AddDate(0, 0, d.Get("rotation_grace_period_days").(int)).Format(time.RFC3339))
+	}
+
 	return resourceConnectedAppRead(ctx, d, m)
 }
 
@@ -546,6 +650,86 @@ func newConnectedAppPatchBody(d *schema.ResourceData) *connApp.ConnectedAppPatch
 	return body
 }
 
+/*
+Rejects the scopes and public keys when they are managed separately,
+validates the scopes against the catalog of known scopes when requested and
+plans the secret rotation when the secret is older than rotate_after_days
+*/
+func resourceConnectedAppCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.Get("scopes_managed_separately").(bool) {
+		if scopes := d.GetRawConfig().GetAttr("scope"); scopes.IsKnown() && !scopes.IsNull() && scopes.LengthInt() > 0 {
+			return fmt.Errorf("scope cannot be set when scopes_managed_separately is true")
+		}
+	}
+	if d.Get("public_keys_managed_separately").(bool) {
+		if keys := d.GetRawConfig().GetAttr("public_keys"); keys.IsKnown() && !keys.IsNull() && keys.LengthInt() > 0 {
+			return fmt.Errorf("public_keys cannot be set when public_keys_managed_separately is true")
+		}
+	}
+	if d.Get("validate_scopes").(bool) && !d.Get("scopes_managed_separately").(bool) && d.NewValueKnown("scope") {
+		for i, scope := range d.Get("scope").([]interface{}) {
+			if scope == nil || !isConnectedAppScopeKnown(d, fmt.Sprintf("scope.%d.", i)) {
+				continue
+			}
+			scope_map := scope.(map[string]interface{})
+			if err := validateConnectedAppScope(scope_map["scope"].(string), scope_map["org_id"].(string), scope_map["env_id"].(string)); err != nil {
+				return err
+			}
+		}
+	}
+	if d.Id() == "" {
+		return nil
+	}
+	if d.HasChange("rotation_trigger") || isConnectedAppSecretRotationDue(d.Get("secret_created_at").(string), d.Get("rotate_after_days").(int)) {
+		for _, attr := range []string{"secret_created_at", "previous_secret", "previous_secret_expires_at"} {
+			if err := d.SetNewComputed(attr); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// returns true if the scope, org_id and env_id under the given prefix are known at plan time
+func isConnectedAppScopeKnown(d *schema.ResourceDiff, prefix string) bool {
+	for _, attr := range []string{"scope", "org_id", "env_id"} {
+		if !d.NewValueKnown(prefix + attr) {
+			return false
+		}
+	}
+	return true
+}
+
+// returns true if the secret created at the given time should be rotated, a secret of unknown age is never rotated
+func isConnectedAppSecretRotationDue(createdat string, rotateafterdays int) bool {
+	if rotateafterdays <= 0 {
+		return false
+	}
+	t, err := time.Parse(time.RFC3339, createdat)
+	if err != nil {
+		return false
+	}
+	return !time.Now().Before(t.AddDate(0, 0, rotateafterdays))
+}
+
+/*
+Regenerates the secret of the connected app using the platform's regeneration endpoint.
+Returns the new secret.
+*/
+func regenerateConnectedAppSecret(ctx context.Context, pco *ProviderConfOutput, connappid string) (string, error) {
+	var res struct {
+		ClientSecret string `json:"client_secret"`
+	}
+	path := "/accounts/api/connectedApplications/" + connappid + "/client_secret"
+	if _, err := doAnypointRequest(ctx, pco, http.MethodPost, path, nil, &res); err != nil {
+		return "", err
+	}
+	if res.ClientSecret == "" {
+		return "", fmt.Errorf("no secret has been returned for connected app %s", connappid)
+	}
+	return res.ClientSecret, nil
+}
+
 // Compares 2 scopes lists
 // returns true if they are the same, false otherwise
 func equalsConnectedAppScopes(old, new interface{}) bool {
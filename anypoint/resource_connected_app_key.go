@@ -0,0 +1,402 @@
+package anypoint
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	connApp "github.com/mulesoft-anypoint/anypoint-client-go/connected_app"
+)
+
+func resourceConnectedAppKey() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceConnectedAppKeyCreate,
+		ReadContext:   resourceConnectedAppKeyRead,
+		DeleteContext: resourceConnectedAppKeyDelete,
+		Description: `
+		Generates an RSA or EC key pair used by a ` + "`" + `connected app` + "`" + ` for jwt-bearer grants and registers its self-signed certificate in the connected app's public keys.
+		The other public keys of the connected app are left untouched, several keys can be registered at the same time to rotate them without downtime.
+		The connected app must set ` + "`" + `public_keys_managed_separately` + "`" + ` to true so that its own ` + "`" + `public_keys` + "`" + ` do not remove the registered keys.
+		The private key is stored unencrypted in the terraform state.
+		`,
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The unique id of this key composed by {connected_app_id}/{fingerprint_sha256}",
+			},
+			"connected_app_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The id of the connected app.",
+			},
+			"algorithm": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ForceNew:         true,
+				Default:          "RSA",
+				Description:      "The algorithm of the key pair. Enum values: RSA, ECDSA",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice([]string{"RSA", "ECDSA"}, false)),
+			},
+			"rsa_bits": {
+				Type:             schema.TypeInt,
+				Optional:         true,
+				ForceNew:         true,
+				Default:          2048,
+				Description:      "The size of the RSA key in bits. Enum values: 2048, 3072, 4096",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntInSlice([]int{2048, 3072, 4096})),
+			},
+			"ecdsa_curve": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ForceNew:         true,
+				Default:          "P256",
+				Description:      "The elliptic curve of the ECDSA key. Enum values: P256, P384, P521",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice([]string{"P256", "P384", "P521"}, false)),
+			},
+			"validity_days": {
+				Type:             schema.TypeInt,
+				Optional:         true,
+				ForceNew:         true,
+				Default:          365,
+				Description:      "The number of days the key's certificate is valid.",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(1)),
+			},
+			"private_key_pem": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "The private key in PEM (PKCS#8) format, used to sign the JWT assertions.",
+			},
+			"public_key_pem": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The public key in PEM (PKIX) format.",
+			},
+			"certificate_pem": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The self-signed certificate registered in the connected app's public keys.",
+			},
+			"fingerprint_sha256": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The SHA-256 fingerprint of the certificate (hex encoded).",
+			},
+			"not_after": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The expiration date of the certificate (RFC3339 format).",
+			},
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+	}
+}
+
+func resourceConnectedAppKeyCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	pco := m.(ProviderConfOutput)
+	connappid := d.Get("connected_app_id").(string)
+
+	key, err := genConnectedAppKey(d.Get("algorithm").(string), d.Get("rsa_bits").(int), d.Get("ecdsa_curve").(string))
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to generate key pair for connected app " + connappid,
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	cert, err := genConnectedAppKeyCertificate(key, connappid, d.Get("validity_days").(int))
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to generate certificate for connected app " + connappid,
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	privatekey, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to encode private key for connected app " + connappid,
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	publickey, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to encode public key for connected app " + connappid,
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	certpem := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+	fingerprint := connectedAppKeyFingerprint(cert.Raw)
+
+	modify := func(list []string) []string {
+		if indexOfConnectedAppKey(list, fingerprint) >= 0 {
+			return list
+		}
+		return append(list, certpem)
+	}
+	check := func(list []string) bool {
+		return indexOfConnectedAppKey(list, fingerprint) >= 0
+	}
+	diags = modifyConnectedAppKeys(ctx, &pco, connappid, d.Timeout(schema.TimeoutCreate), modify, check)
+	if diags.HasError() {
+		return diags
+	}
+
+	d.SetId(ComposeResourceId([]string{connappid, fingerprint}))
+	d.Set("private_key_pem", string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privatekey})))
+	d.Set("public_key_pem", string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publickey})))
+	d.Set("certificate_pem", certpem)
+	d.Set("fingerprint_sha256", fingerprint)
+	d.Set("not_after", cert.NotAfter.UTC().Format(time.RFC3339))
+
+	return resourceConnectedAppKeyRead(ctx, d, m)
+}
+
+func resourceConnectedAppKeyRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	pco := m.(ProviderConfOutput)
+	connappid, fingerprint, err := decomposeConnectedAppKeyId(d)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Invalid connected app key id",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	authctx := getConnectedAppAuthCtx(ctx, &pco)
+
+	connapp, err := getConnectedApp(authctx, &pco, connappid)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to Get Connected App " + connappid,
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	if indexOfConnectedAppKey(connapp.GetPublicKeys(), fingerprint) < 0 {
+		// the key has been removed outside terraform
+		d.SetId("")
+		return diags
+	}
+
+	return diags
+}
+
+func resourceConnectedAppKeyDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	pco := m.(ProviderConfOutput)
+	connappid, fingerprint, err := decomposeConnectedAppKeyId(d)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Invalid connected app key id",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	modify := func(list []string) []string {
+		result := make([]string, 0, len(list))
+		for _, key := range list {
+			if connectedAppKeyMatches(key, fingerprint) {
+				continue
+			}
+			result = append(result, key)
+		}
+		return result
+	}
+	check := func(list []string) bool {
+		return indexOfConnectedAppKey(list, fingerprint) < 0
+	}
+	diags = modifyConnectedAppKeys(ctx, &pco, connappid, d.Timeout(schema.TimeoutDelete), modify, check)
+	if diags.HasError() {
+		return diags
+	}
+	// d.SetId("") is automatically called assuming delete returns no errors, but
+	// it is added here for explicitness.
+	d.SetId("")
+
+	return diags
+}
+
+/*
+Performs a read-modify-write of the connected app's public keys.
+The written keys are read back and verified using the check function,
+the whole cycle is retried if a concurrent modification is detected.
+*/
+func modifyConnectedAppKeys(ctx context.Context, pco *ProviderConfOutput, connappid string, timeout time.Duration, modify func([]string) []string, check func([]string) bool) diag.Diagnostics {
+	var diags diag.Diagnostics
+	authctx := getConnectedAppAuthCtx(ctx, pco)
+	key := "connected app " + connappid + " public keys"
+
+	write := func() *resource.RetryError {
+		current, err := getConnectedApp(authctx, pco, connappid)
+		if err != nil {
+			return resource.NonRetryableError(err)
+		}
+		body := newConnectedAppPatchBodyFromResp(current)
+		body.SetPublicKeys(modify(current.GetPublicKeys()))
+		_, httpr, err := pco.connectedappclient.DefaultApi.ConnectedApplicationsConnAppIdPatch(authctx, connappid).ConnectedAppPatchExt(*body).Execute()
+		if err != nil {
+			return readModifyWriteError(key, httpr, err)
+		}
+		httpr.Body.Close()
+		return nil
+	}
+	verify := func() (bool, error) {
+		written, err := getConnectedApp(authctx, pco, connappid)
+		if err != nil {
+			return false, err
+		}
+		return check(written.GetPublicKeys()), nil
+	}
+	if err := readModifyWrite(ctx, key, timeout, write, verify); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to update connected app " + connappid + " public keys",
+			Detail:   err.Error(),
+		})
+	}
+
+	return diags
+}
+
+func getConnectedApp(authctx context.Context, pco *ProviderConfOutput, connappid string) (*connApp.ConnectedAppRespExt, error) {
+	res, httpr, err := pco.connectedappclient.DefaultApi.ConnectedApplicationsConnAppIdGet(authctx, connappid).Execute()
+	if err != nil {
+		var details string
+		if httpr != nil {
+			b, _ := ioutil.ReadAll(httpr.Body)
+			details = string(b)
+		} else {
+			details = err.Error()
+		}
+		return nil, fmt.Errorf("unable to get connected app %s\n details: %s", connappid, details)
+	}
+	httpr.Body.Close()
+	return &res, nil
+}
+
+// prepares a patch body keeping the connected app's current attributes
+func newConnectedAppPatchBodyFromResp(connapp *connApp.ConnectedAppRespExt) *connApp.ConnectedAppPatchExt {
+	body := connApp.NewConnectedAppPatchExtWithDefaults()
+	body.SetClientName(connapp.GetClientName())
+	body.SetGrantTypes(connapp.GetGrantTypes())
+	body.SetAudience(connapp.GetAudience())
+	body.SetRedirectUris(connapp.GetRedirectUris())
+	// "on its own behalf" connected apps' scopes are managed through the scopes endpoint
+	if StringInSlice(connapp.GetGrantTypes(), "client_credentials", true) {
+		body.SetScopes(make([]string, 0))
+	} else {
+		body.SetScopes(connapp.GetScopes())
+	}
+	body.SetEnabled(connapp.GetEnabled())
+	if clienturi := connapp.GetClientUri(); clienturi != "" {
+		body.SetClientUri(clienturi)
+	}
+	return body
+}
+
+func genConnectedAppKey(algorithm string, rsabits int, curve string) (crypto.Signer, error) {
+	if algorithm == "ECDSA" {
+		curves := map[string]elliptic.Curve{
+			"P256": elliptic.P256(),
+			"P384": elliptic.P384(),
+			"P521": elliptic.P521(),
+		}
+		c, ok := curves[curve]
+		if !ok {
+			return nil, fmt.Errorf("unsupported elliptic curve %s", curve)
+		}
+		return ecdsa.GenerateKey(c, rand.Reader)
+	}
+	return rsa.GenerateKey(rand.Reader, rsabits)
+}
+
+// generates the self-signed certificate of the given key
+func genConnectedAppKeyCertificate(key crypto.Signer, connappid string, validitydays int) (*x509.Certificate, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: connappid},
+		NotBefore:             now.Add(-5 * time.Minute),
+		NotAfter:              now.AddDate(0, 0, validitydays),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParseCertificate(der)
+}
+
+func connectedAppKeyFingerprint(der []byte) string {
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:])
+}
+
+// returns true if the given registered public key is the certificate with the given fingerprint
+func connectedAppKeyMatches(key string, fingerprint string) bool {
+	if block, _ := pem.Decode([]byte(key)); block != nil {
+		return connectedAppKeyFingerprint(block.Bytes) == fingerprint
+	}
+	// keys may be registered without PEM armor
+	der, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(key), ""))
+	return err == nil && connectedAppKeyFingerprint(der) == fingerprint
+}
+
+// returns the index of the public key with the given fingerprint, -1 if not found
+func indexOfConnectedAppKey(list []string, fingerprint string) int {
+	for i, key := range list {
+		if connectedAppKeyMatches(key, fingerprint) {
+			return i
+		}
+	}
+	return -1
+}
+
+func decomposeConnectedAppKeyId(d *schema.ResourceData) (string, string, error) {
+	s := DecomposeResourceId(d.Id())
+	if len(s) != 2 {
+		return "", "", fmt.Errorf("invalid connected app key id %q, expected {connected_app_id}%s{fingerprint_sha256}", d.Id(), COMPOSITE_ID_SEPARATOR)
+	}
+	return s[0], s[1], nil
+}
@@ -0,0 +1,292 @@
+package anypoint
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	connApp "github.com/mulesoft-anypoint/anypoint-client-go/connected_app"
+)
+
+func resourceConnectedAppScope() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceConnectedAppScopeCreate,
+		ReadContext:   resourceConnectedAppScopeRead,
+		DeleteContext: resourceConnectedAppScopeDelete,
+		CustomizeDiff: resourceConnectedAppScopeCustomizeDiff,
+		Description: `
+		Grants a single scope, in a given business group and environment context, to an existing "on its own behalf" ` + "`" + `connected app` + "`" + `.
+		The other scopes of the connected app are left untouched.
+		The connected app must set ` + "`" + `scopes_managed_separately` + "`" + ` to true so that its own ` + "`" + `scope` + "`" + ` blocks do not remove the granted scopes.
+		`,
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The unique id of this scope grant composed by {connected_app_id}/{scope}/{org_id}/{env_id}",
+			},
+			"connected_app_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The id of the connected app.",
+			},
+			"scope": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The scope to grant.",
+			},
+			"org_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     "",
+				Description: "The id of the business group the scope is valid. Only required for particular scopes",
+			},
+			"env_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     "",
+				Description: "The id of the environment the scope is valid. Only required for particular scopes",
+			},
+			"validate_scope": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     true,
+				Description: "Whether the scope is validated at plan time against the catalog of scopes known by this provider (see data source anypoint_connected_app_scopes). A scope that is not known at plan time is only checked by the platform. Set it to false for scopes the catalog doesn't list yet.",
+			},
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+	}
+}
+
+func resourceConnectedAppScopeCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	pco := m.(ProviderConfOutput)
+	connappid := d.Get("connected_app_id").(string)
+	scope := d.Get("scope").(string)
+	orgid := d.Get("org_id").(string)
+	envid := d.Get("env_id").(string)
+
+	modify := func(list []interface{}) []interface{} {
+		if indexOfConnectedAppScope(list, scope, orgid, envid) >= 0 {
+			return list
+		}
+		return append(list, map[string]interface{}{"scope": scope, "org_id": orgid, "env_id": envid})
+	}
+	check := func(list []interface{}) bool {
+		return indexOfConnectedAppScope(list, scope, orgid, envid) >= 0
+	}
+	diags := modifyConnectedAppScopes(ctx, &pco, connappid, d.Timeout(schema.TimeoutCreate), modify, check)
+	if diags.HasError() {
+		return diags
+	}
+
+	d.SetId(ComposeResourceId([]string{connappid, scope, orgid, envid}))
+
+	return resourceConnectedAppScopeRead(ctx, d, m)
+}
+
+func resourceConnectedAppScopeRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	pco := m.(ProviderConfOutput)
+	connappid, scope, orgid, envid, err := decomposeConnectedAppScopeId(d)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Invalid connected app scope id",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	authctx := getConnectedAppAuthCtx(ctx, &pco)
+
+	list, err := getConnectedAppScopes(authctx, &pco, connappid)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to get connected app " + connappid + " scopes",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	if indexOfConnectedAppScope(list, scope, orgid, envid) < 0 {
+		// the scope has been revoked outside terraform
+		d.SetId("")
+		return diags
+	}
+
+	// setting resource id components for import purposes
+	d.Set("connected_app_id", connappid)
+	d.Set("scope", scope)
+	d.Set("org_id", orgid)
+	d.Set("env_id", envid)
+
+	return diags
+}
+
+func resourceConnectedAppScopeDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	pco := m.(ProviderConfOutput)
+	connappid, scope, orgid, envid, err := decomposeConnectedAppScopeId(d)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Invalid connected app scope id",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	modify := func(list []interface{}) []interface{} {
+		return FilterMapList(list, func(item map[string]interface{}) bool {
+			return !isConnectedAppScope(item, scope, orgid, envid)
+		})
+	}
+	check := func(list []interface{}) bool {
+		return indexOfConnectedAppScope(list, scope, orgid, envid) < 0
+	}
+	diags = modifyConnectedAppScopes(ctx, &pco, connappid, d.Timeout(schema.TimeoutDelete), modify, check)
+	if diags.HasError() {
+		return diags
+	}
+	// d.SetId("") is automatically called assuming delete returns no errors, but
+	// it is added here for explicitness.
+	d.SetId("")
+
+	return diags
+}
+
+func resourceConnectedAppScopeCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.Get("validate_scope").(bool) || !isConnectedAppScopeKnown(d, "") {
+		return nil
+	}
+	return validateConnectedAppScope(d.Get("scope").(string), d.Get("org_id").(string), d.Get("env_id").(string))
+}
+
+/*
+Performs a read-modify-write of the connected app's scopes.
+The written scopes are read back and verified using the check function,
+the whole cycle is retried if a concurrent modification is detected.
+*/
+func modifyConnectedAppScopes(ctx context.Context, pco *ProviderConfOutput, connappid string, timeout time.Duration, modify func([]interface{}) []interface{}, check func([]interface{}) bool) diag.Diagnostics {
+	var diags diag.Diagnostics
+	authctx := getConnectedAppAuthCtx(ctx, pco)
+	key := "connected app " + connappid + " scopes"
+
+	write := func() *resource.RetryError {
+		current, err := getConnectedAppScopes(authctx, pco, connappid)
+		if err != nil {
+			return resource.NonRetryableError(err)
+		}
+		body := connectedAppScopesList2PutBody(modify(current))
+		httpr, err := pco.connectedappclient.DefaultApi.ConnectedApplicationsConnAppIdScopesPut(authctx, connappid).ConnectedAppScopesPutBody(*body).Execute()
+		if err != nil {
+			return readModifyWriteError(key, httpr, err)
+		}
+		httpr.Body.Close()
+		return nil
+	}
+	verify := func() (bool, error) {
+		written, err := getConnectedAppScopes(authctx, pco, connappid)
+		if err != nil {
+			return false, err
+		}
+		return check(written), nil
+	}
+	if err := readModifyWrite(ctx, key, timeout, write, verify); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to update connected app " + connappid + " scopes",
+			Detail:   err.Error(),
+		})
+	}
+
+	return diags
+}
+
+// loads the connected app's scopes as a list of maps with scope, org_id and env_id as strings
+func getConnectedAppScopes(authctx context.Context, pco *ProviderConfOutput, connappid string) ([]interface{}, error) {
+	res, httpr, err := pco.connectedappclient.DefaultApi.ConnectedApplicationsConnAppIdScopesGet(authctx, connappid).Execute()
+	if err != nil {
+		var details string
+		if httpr != nil {
+			b, _ := ioutil.ReadAll(httpr.Body)
+			details = string(b)
+		} else {
+			details = err.Error()
+		}
+		return nil, fmt.Errorf("unable to get connected app %s scopes\n details: %s", connappid, details)
+	}
+	httpr.Body.Close()
+
+	list := make([]interface{}, len(res.GetData()))
+	for i, scope := range res.GetData() {
+		item := map[string]interface{}{"scope": scope.GetScope(), "org_id": "", "env_id": ""}
+		if contextparams, ok := scope.GetContextParamsOk(); ok {
+			item["org_id"] = contextparams.GetOrg()
+			item["env_id"] = contextparams.GetEnvId()
+		}
+		list[i] = item
+	}
+	return list, nil
+}
+
+func connectedAppScopesList2PutBody(list []interface{}) *connApp.ConnectedAppScopesPutBody {
+	body := connApp.NewConnectedAppScopesPutBodyWithDefaults()
+	scopes := make([]connApp.ScopeCore, len(list))
+	for i, item := range list {
+		data := item.(map[string]interface{})
+		scope := connApp.NewScopeCoreWithDefaults()
+		scope.SetScope(data["scope"].(string))
+		contextparams := connApp.NewContextParamsWithDefaults()
+		if orgid := data["org_id"].(string); orgid != "" {
+			contextparams.SetOrg(orgid)
+		}
+		if envid := data["env_id"].(string); envid != "" {
+			contextparams.SetEnvId(envid)
+		}
+		scope.SetContextParams(*contextparams)
+		scopes[i] = *scope
+	}
+	body.SetScopes(scopes)
+	return body
+}
+
+// returns the index of the given scope grant, -1 if not found
+func indexOfConnectedAppScope(list []interface{}, scope, orgid, envid string) int {
+	for i, item := range list {
+		if isConnectedAppScope(item.(map[string]interface{}), scope, orgid, envid) {
+			return i
+		}
+	}
+	return -1
+}
+
+func isConnectedAppScope(item map[string]interface{}, scope, orgid, envid string) bool {
+	s, _ := item["scope"].(string)
+	o, _ := item["org_id"].(string)
+	e, _ := item["env_id"].(string)
+	return strings.EqualFold(s, scope) && o == orgid && e == envid
+}
+
+func decomposeConnectedAppScopeId(d *schema.ResourceData) (string, string, string, string, error) {
+	s := DecomposeResourceId(d.Id())
+	if len(s) != 4 {
+		return "", "", "", "", fmt.Errorf("invalid connected app scope id %q, expected {connected_app_id}%[2]s{scope}%[2]s{org_id}%[2]s{env_id}, org_id and env_id may be empty", d.Id(), COMPOSITE_ID_SEPARATOR)
+	}
+	return s[0], s[1], s[2], s[3], nil
+}
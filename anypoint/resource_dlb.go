@@ -345,6 +345,9 @@ func resourceDLB() *schema.Resource {
 				Description: "Setting this to true will forward any incoming client certificates to upstream application",
 			},
 		},
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateCompositeId("org_id", "vpc_id"),
+		},
 	}
 }
 
@@ -2,7 +2,10 @@ package anypoint
 
 import (
 	"context"
+	"fmt"
 	"io/ioutil"
+	"net/http"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
@@ -18,8 +21,10 @@ func resourceENV() *schema.Resource {
 		ReadContext:   resourceENVRead,
 		UpdateContext: resourceENVUpdate,
 		DeleteContext: resourceENVDelete,
+		CustomizeDiff: resourceENVCustomizeDiff,
 		Description: `
 		Creates an ` + "`" + `environement` + "`" + ` for your ` + "`" + `org` + "`" + `.
+		The environment can only be deleted when ` + "`" + `deletion_protection` + "`" + ` is disabled and it no longer contains MQ destinations, applications or VPC associations.
 		`,
 		Schema: map[string]*schema.Schema{
 			"last_updated": {
@@ -61,6 +66,15 @@ func resourceENV() *schema.Resource {
 				Computed:    true,
 				Description: "The environment client id",
 			},
+			"deletion_protection": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Computed:    true,
+				Description: "Whether the environment is protected against deletion. Defaults to true for production environments and false otherwise.",
+			},
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateCompositeId("org_id"),
 		},
 	}
 }
@@ -184,6 +198,39 @@ func resourceENVDelete(ctx context.Context, d *schema.ResourceData, m interface{
 	envid := d.Id()
 	orgid := d.Get("org_id").(string)
 
+	if d.Get("deletion_protection").(bool) {
+		diags := append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to Delete ENV " + envid,
+			Detail:   "The environment is protected against deletion, set deletion_protection to false and apply before deleting it.",
+		})
+		return diags
+	}
+	blockers, warnings, err := getENVDeletionBlockers(ctx, &pco, orgid, envid)
+	for _, w := range warnings {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "Unable to check ENV " + envid + " inventory",
+			Detail:   w,
+		})
+	}
+	if err != nil {
+		diags := append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to check ENV " + envid + " inventory",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	if len(blockers) > 0 {
+		diags := append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to Delete ENV " + envid,
+			Detail:   "The environment still contains the following resources, remove them before deleting it:\n - " + strings.Join(blockers, "\n - "),
+		})
+		return diags
+	}
+
 	authctx := getENVAuthCtx(ctx, &pco)
 
 	httpr, err := pco.envclient.DefaultApi.OrganizationsOrgIdEnvironmentsEnvironmentIdDelete(authctx, orgid, envid).Execute()
@@ -239,3 +286,136 @@ func getENVAuthCtx(ctx context.Context, pco *ProviderConfOutput) context.Context
 	tmp := context.WithValue(ctx, env.ContextAccessToken, pco.access_token)
 	return context.WithValue(tmp, env.ContextServerIndex, pco.server_index)
 }
+
+/*
+ * Sets the default deletion protection, production environments are protected unless explicitly configured
+ */
+func resourceENVCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.GetRawConfig().GetAttr("deletion_protection").IsNull() || !d.NewValueKnown("type") {
+		return nil
+	}
+	return d.SetNew("deletion_protection", strings.EqualFold(d.Get("type").(string), "production"))
+}
+
+// the regions where anypoint MQ destinations can be created
+var ENV_MQ_REGIONS = []string{
+	"us-east-1", "us-east-2", "us-west-2", "ca-central-1", "eu-west-1", "eu-west-2",
+	"ap-southeast-1", "ap-southeast-2", "ap-northeast-1", "eu-central-1",
+}
+
+/*
+Lists the resources that prevent the deletion of the given environment: MQ destinations, applications and VPC associations.
+The inventories that cannot be read because the product is not available to the organization or to the provider's credentials
+are skipped and reported as warnings.
+*/
+func getENVDeletionBlockers(ctx context.Context, pco *ProviderConfOutput, orgid string, envid string) ([]string, []string, error) {
+	blockers := make([]string, 0)
+	warnings := make([]string, 0)
+
+	//MQ queues and exchanges
+	for _, region := range ENV_MQ_REGIONS {
+		var destinations []struct {
+			QueueId    string `json:"queueId"`
+			ExchangeId string `json:"exchangeId"`
+		}
+		path := fmt.Sprintf("/mq/admin/api/v1/organizations/%s/environments/%s/regions/%s/destinations", orgid, envid, region)
+		httpr, err := doAnypointRequest(ctx, pco, http.MethodGet, path, nil, &destinations)
+		if err != nil {
+			if httpr != nil && isMQRegionNotEnabled(httpr.StatusCode, err.Error()) {
+				continue
+			}
+			if httpr != nil && httpr.StatusCode == http.StatusForbidden {
+				warnings = append(warnings, "MQ destinations in region "+region+" were not checked: "+err.Error())
+				continue
+			}
+			return nil, nil, err
+		}
+		for _, dest := range destinations {
+			if dest.ExchangeId != "" {
+				blockers = append(blockers, fmt.Sprintf("MQ exchange %s (%s)", dest.ExchangeId, region))
+			} else {
+				blockers = append(blockers, fmt.Sprintf("MQ queue %s (%s)", dest.QueueId, region))
+			}
+		}
+	}
+
+	//cloudhub applications
+	var chapps []struct {
+		Domain string `json:"domain"`
+	}
+	headers := map[string]string{"X-ANYPNT-ORG-ID": orgid, "X-ANYPNT-ENV-ID": envid}
+	if httpr, err := doAnypointRequestWithHeaders(ctx, pco, http.MethodGet, "/cloudhub/api/v2/applications", headers, nil, &chapps); err != nil {
+		if !isENVInventoryUnavailable(httpr) {
+			return nil, nil, err
+		}
+		warnings = append(warnings, "CloudHub applications were not checked: "+err.Error())
+	}
+	for _, app := range chapps {
+		blockers = append(blockers, "CloudHub application "+app.Domain)
+	}
+
+	//runtime manager deployments (cloudhub 2.0, runtime fabric)
+	var deployments struct {
+		Items []struct {
+			Name string `json:"name"`
+		} `json:"items"`
+	}
+	path := fmt.Sprintf("/amc/application-manager/api/v2/organizations/%s/environments/%s/deployments", orgid, envid)
+	if httpr, err := doAnypointRequest(ctx, pco, http.MethodGet, path, nil, &deployments); err != nil {
+		if !isENVInventoryUnavailable(httpr) {
+			return nil, nil, err
+		}
+		warnings = append(warnings, "Runtime Manager deployments were not checked: "+err.Error())
+	}
+	for _, dep := range deployments.Items {
+		blockers = append(blockers, "application deployment "+dep.Name)
+	}
+
+	//VPC associations
+	authctx := getVPCAuthCtx(ctx, pco)
+	res, httpr, err := pco.vpcclient.DefaultApi.OrganizationsOrgIdVpcsGet(authctx, orgid).Execute()
+	if err != nil {
+		if !isENVInventoryUnavailable(httpr) {
+			var details string
+			if httpr != nil {
+				b, _ := ioutil.ReadAll(httpr.Body)
+				details = string(b)
+			} else {
+				details = err.Error()
+			}
+			return nil, nil, fmt.Errorf("unable to get VPCs: %s", details)
+		}
+		warnings = append(warnings, "VPC associations were not checked: "+err.Error())
+	} else {
+		defer httpr.Body.Close()
+		for _, vpcitem := range res.GetData() {
+			if StringInSlice(vpcitem.GetAssociatedEnvironments(), envid, false) {
+				blockers = append(blockers, fmt.Sprintf("VPC %s (%s) association", vpcitem.GetName(), vpcitem.GetId()))
+			}
+		}
+	}
+
+	return blockers, warnings, nil
+}
+
+// the messages MQ answers with on the regions that are not enabled for the organization
+var MQ_REGION_NOT_ENABLED_MESSAGES = []string{"not enabled", "not available", "not supported"}
+
+// true when MQ answers that the region is not enabled for the organization, any other client error is a real failure
+func isMQRegionNotEnabled(status int, details string) bool {
+	if status != http.StatusBadRequest && status != http.StatusNotFound {
+		return false
+	}
+	details = strings.ToLower(details)
+	for _, msg := range MQ_REGION_NOT_ENABLED_MESSAGES {
+		if strings.Contains(details, msg) {
+			return true
+		}
+	}
+	return false
+}
+
+// true when the inventory is not readable because the product is not available to the org or the credentials
+func isENVInventoryUnavailable(httpr *http.Response) bool {
+	return httpr != nil && (httpr.StatusCode == http.StatusForbidden || httpr.StatusCode == http.StatusNotFound)
+}
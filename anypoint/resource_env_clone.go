@@ -0,0 +1,975 @@
+package anypoint
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	ame "github.com/mulesoft-anypoint/anypoint-client-go/ame"
+	ame_binding "github.com/mulesoft-anypoint/anypoint-client-go/ame_binding"
+	amq "github.com/mulesoft-anypoint/anypoint-client-go/amq"
+	env "github.com/mulesoft-anypoint/anypoint-client-go/env"
+	vpc "github.com/mulesoft-anypoint/anypoint-client-go/vpc"
+)
+
+// the page size used to list the source environment's MQ destinations
+const ENV_CLONE_DESTINATIONS_PAGE_SIZE = 20
+
+// serializes the read-modify-write cycles performed on the same VPC's associated environments by this provider
+var vpcAssociationLocks sync.Map
+
+// the objects replicated into the cloned environment, they are destroyed along with the clone
+type envCloneTracked struct {
+	queues    []interface{}
+	exchanges []interface{}
+	bindings  []interface{}
+	vpcIds    []string
+	teamRoles []interface{}
+}
+
+func resourceENVClone() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceENVCloneCreate,
+		ReadContext:   resourceENVCloneRead,
+		UpdateContext: resourceENVCloneUpdate,
+		DeleteContext: resourceENVCloneDelete,
+		Description: `
+		Creates an ` + "`" + `environment` + "`" + ` replicating the configuration of an existing one in the same ` + "`" + `org` + "`" + `.
+		The selected categories (MQ queues, MQ exchanges and their bindings, VPC associations and environment scoped team roles) are copied from the source environment.
+		The replicated objects are tracked in the state and destroyed along with the cloned environment,
+		the changes made to the source environment after the clone are not replicated.
+		The VPCs managed by ` + "`" + `anypoint_vpc` + "`" + ` must be listed in ` + "`" + `skip_vpc_ids` + "`" + `, the association is then declared in their ` + "`" + `associated_environments` + "`" + `.
+		`,
+		Schema: map[string]*schema.Schema{
+			"last_updated": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "The last time this resource has been updated locally.",
+			},
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The unique id of the cloned environment generated by the anypoint platform.",
+			},
+			"org_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The organization id where the source environment is defined and the clone is created.",
+			},
+			"source_env_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The id of the environment to clone.",
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The name of the cloned environment.",
+			},
+			"type": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				ForceNew:         true,
+				Description:      "The type of the cloned environment: sandbox, design or production. Defaults to the type of the source environment.",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice([]string{"sandbox", "design", "production"}, true)),
+			},
+			"regions": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "The MQ regions where the queues and exchanges are replicated. Defaults to all the regions.",
+				Elem: &schema.Schema{
+					Type:             schema.TypeString,
+					ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice(ENV_MQ_REGIONS, false)),
+				},
+			},
+			"clone_queues": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				ForceNew:    true,
+				Description: "Whether the MQ queues are replicated.",
+			},
+			"clone_exchanges": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				ForceNew:    true,
+				Description: "Whether the MQ exchanges are replicated. The bindings are replicated as well when the queues are.",
+			},
+			"clone_vpc_association": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				ForceNew:    true,
+				Description: "Whether the cloned environment is associated to the VPCs the source environment is associated to, except the ones listed in skip_vpc_ids.",
+			},
+			"skip_vpc_ids": {
+				Type:     schema.TypeList,
+				Optional: true,
+				ForceNew: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Description: `
+				The VPCs the cloned environment is not associated to by this resource.
+				List here the VPCs managed by anypoint_vpc, their associated_environments would otherwise remove the association on the next apply.
+				Add the cloned environment to their associated_environments instead.
+				`,
+			},
+			"clone_team_roles": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				ForceNew:    true,
+				Description: "Whether the teams holding roles scoped to the source environment are granted the same roles on the cloned environment.",
+			},
+			"is_production": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "True if the cloned environment is a production environment",
+			},
+			"client_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The cloned environment client id",
+			},
+			"queues": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The queues replicated into the cloned environment.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"region_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The region of the queue.",
+						},
+						"queue_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The queue id.",
+						},
+						"dead_letter_queue_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The dead letter queue of the queue if any.",
+						},
+					},
+				},
+			},
+			"exchanges": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The exchanges replicated into the cloned environment.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"region_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The region of the exchange.",
+						},
+						"exchange_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The exchange id.",
+						},
+					},
+				},
+			},
+			"bindings": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The exchange bindings replicated into the cloned environment.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"region_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The region of the binding.",
+						},
+						"exchange_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The bound exchange id.",
+						},
+						"queue_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The bound queue id.",
+						},
+					},
+				},
+			},
+			"vpc_ids": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The VPCs the cloned environment has been associated to.",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"team_roles": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The team roles granted on the cloned environment.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"team_org_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The master organization id where the team is defined.",
+						},
+						"team_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The team id.",
+						},
+						"role_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The role id.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func resourceENVCloneCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	pco := m.(ProviderConfOutput)
+	orgid := d.Get("org_id").(string)
+	sourceid := d.Get("source_env_id").(string)
+
+	authctx := getENVAuthCtx(ctx, &pco)
+
+	//read source environment
+	source, httpr, err := pco.envclient.DefaultApi.OrganizationsOrgIdEnvironmentsEnvironmentIdGet(authctx, orgid, sourceid).Execute()
+	if err != nil {
+		var details string
+		if httpr != nil {
+			b, _ := ioutil.ReadAll(httpr.Body)
+			details = string(b)
+		} else {
+			details = err.Error()
+		}
+		diags := append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to Get source ENV " + sourceid,
+			Detail:   details,
+		})
+		return diags
+	}
+	httpr.Body.Close()
+
+	//request env creation
+	body := env.NewEnvCoreWithDefaults()
+	body.SetName(d.Get("name").(string))
+	if v := d.Get("type").(string); v != "" {
+		body.SetType(v)
+	} else {
+		body.SetType(source.GetType())
+	}
+	res, httpr, err := pco.envclient.DefaultApi.OrganizationsOrgIdEnvironmentsPost(authctx, orgid).EnvCore(*body).Execute()
+	if err != nil {
+		var details string
+		if httpr != nil {
+			b, _ := ioutil.ReadAll(httpr.Body)
+			details = string(b)
+		} else {
+			details = err.Error()
+		}
+		diags := append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to Create ENV",
+			Detail:   details,
+		})
+		return diags
+	}
+	httpr.Body.Close()
+	envid := res.GetId()
+	d.SetId(envid)
+
+	//replicate the selected categories, the objects are tracked as soon as they are created
+	//so that a partially cloned environment can be destroyed
+	tracked := &envCloneTracked{}
+	//the regions skipped because of the credentials are reported as warnings
+	warnings := make([]string, 0)
+	warn := func(msg string) {
+		warnings = append(warnings, msg)
+	}
+	regions := ListInterface2ListStrings(d.Get("regions").([]interface{}))
+	if len(regions) == 0 {
+		regions = ENV_MQ_REGIONS
+	}
+	steps := []struct {
+		enabled bool
+		summary string
+		clone   func() error
+	}{
+		{d.Get("clone_queues").(bool), "Unable to clone MQ queues", func() error {
+			return cloneENVQueues(ctx, &pco, orgid, sourceid, envid, regions, tracked, warn)
+		}},
+		{d.Get("clone_exchanges").(bool), "Unable to clone MQ exchanges", func() error {
+			return cloneENVExchanges(ctx, &pco, orgid, sourceid, envid, regions, tracked, warn)
+		}},
+		{d.Get("clone_vpc_association").(bool), "Unable to clone VPC associations", func() error {
+			skipped := ListInterface2ListStrings(d.Get("skip_vpc_ids").([]interface{}))
+			return cloneENVVpcAssociations(ctx, &pco, orgid, sourceid, envid, skipped, tracked)
+		}},
+		{d.Get("clone_team_roles").(bool), "Unable to clone team roles", func() error {
+			return cloneENVTeamRoles(ctx, &pco, orgid, sourceid, envid, tracked)
+		}},
+	}
+	for _, step := range steps {
+		if !step.enabled {
+			continue
+		}
+		if err := step.clone(); err != nil {
+			setENVCloneTrackedToResourceData(d, tracked)
+			diags := append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  step.summary + " from ENV " + sourceid,
+				Detail:   err.Error(),
+			})
+			return diags
+		}
+	}
+	setENVCloneTrackedToResourceData(d, tracked)
+
+	diags = resourceENVCloneRead(ctx, d, m)
+	for _, warning := range warnings {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "Incomplete clone of ENV " + sourceid,
+			Detail:   warning,
+		})
+	}
+	return diags
+}
+
+func resourceENVCloneRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	pco := m.(ProviderConfOutput)
+	envid := d.Id()
+	orgid := d.Get("org_id").(string)
+
+	authctx := getENVAuthCtx(ctx, &pco)
+
+	res, httpr, err := pco.envclient.DefaultApi.OrganizationsOrgIdEnvironmentsEnvironmentIdGet(authctx, orgid, envid).Execute()
+	if err != nil {
+		if httpr != nil && httpr.StatusCode == http.StatusNotFound {
+			d.SetId("")
+			return diags
+		}
+		var details string
+		if httpr != nil {
+			b, _ := ioutil.ReadAll(httpr.Body)
+			details = string(b)
+		} else {
+			details = err.Error()
+		}
+		diags := append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to Get ENV " + envid,
+			Detail:   details,
+		})
+		return diags
+	}
+	httpr.Body.Close()
+
+	d.Set("name", res.GetName())
+	d.Set("type", res.GetType())
+	d.Set("is_production", res.GetIsProduction())
+	d.Set("client_id", res.GetClientId())
+
+	return diags
+}
+
+func resourceENVCloneUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	pco := m.(ProviderConfOutput)
+	envid := d.Id()
+	orgid := d.Get("org_id").(string)
+
+	authctx := getENVAuthCtx(ctx, &pco)
+
+	if d.HasChange("name") {
+		body := newENVPutBody(d)
+		_, httpr, err := pco.envclient.DefaultApi.OrganizationsOrgIdEnvironmentsEnvironmentIdPut(authctx, orgid, envid).EnvCore(*body).Execute()
+		if err != nil {
+			var details string
+			if httpr != nil {
+				b, _ := ioutil.ReadAll(httpr.Body)
+				details = string(b)
+			} else {
+				details = err.Error()
+			}
+			diags := append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Unable to Update ENV " + envid,
+				Detail:   details,
+			})
+			return diags
+		}
+		defer httpr.Body.Close()
+
+		d.Set("last_updated", time.Now().Format(time.RFC850))
+	}
+
+	return resourceENVCloneRead(ctx, d, m)
+}
+
+func resourceENVCloneDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	pco := m.(ProviderConfOutput)
+	envid := d.Id()
+	orgid := d.Get("org_id").(string)
+
+	//remove the replicated objects, the ones already removed are ignored
+	tracked := getENVCloneTrackedFromResourceData(d)
+	if err := deleteENVCloneTracked(ctx, &pco, orgid, envid, tracked); err != nil {
+		// keeps track of the objects which are left
+		setENVCloneTrackedToResourceData(d, tracked)
+		diags := append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to delete the objects cloned into ENV " + envid,
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	setENVCloneTrackedToResourceData(d, tracked)
+
+	//the objects created in the environment after the clone are not removed
+	blockers, warnings, err := getENVDeletionBlockers(ctx, &pco, orgid, envid)
+	for _, w := range warnings {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "Unable to check ENV " + envid + " inventory",
+			Detail:   w,
+		})
+	}
+	if err != nil {
+		diags := append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to check ENV " + envid + " inventory",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	if len(blockers) > 0 {
+		diags := append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to Delete ENV " + envid,
+			Detail:   "The environment still contains the following resources, remove them before deleting it:\n - " + strings.Join(blockers, "\n - "),
+		})
+		return diags
+	}
+
+	authctx := getENVAuthCtx(ctx, &pco)
+	httpr, err := pco.envclient.DefaultApi.OrganizationsOrgIdEnvironmentsEnvironmentIdDelete(authctx, orgid, envid).Execute()
+	if err != nil {
+		var details string
+		if httpr != nil {
+			b, _ := ioutil.ReadAll(httpr.Body)
+			details = string(b)
+		} else {
+			details = err.Error()
+		}
+		diags := append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to Delete ENV " + envid,
+			Detail:   details,
+		})
+		return diags
+	}
+	defer httpr.Body.Close()
+	// d.SetId("") is automatically called assuming delete returns no errors, but
+	// it is added here for explicitness.
+	d.SetId("")
+
+	return diags
+}
+
+/*
+Replicates the queues of the source environment.
+The queues are created first without their dead letter queue which is set once all the queues exist.
+*/
+func cloneENVQueues(ctx context.Context, pco *ProviderConfOutput, orgid string, sourceid string, envid string, regions []string, tracked *envCloneTracked, warn func(string)) error {
+	authctx := getAMQAuthCtx(ctx, pco)
+	for _, regionid := range regions {
+		queues, skip, warning, err := listENVCloneDestinations(ctx, pco, orgid, sourceid, regionid, "queue")
+		if err != nil {
+			return err
+		}
+		if warning != "" {
+			warn(warning)
+		}
+		if skip {
+			continue
+		}
+		for _, q := range queues {
+			body := newENVCloneQueueBody(&q, false)
+			_, httpr, err := pco.amqclient.DefaultApi.CreateAMQ(authctx, orgid, envid, regionid, q.GetQueueId()).QueueBody(*body).Execute()
+			if err != nil {
+				return envCloneRequestError(httpr, err, "create queue "+q.GetQueueId()+" in region "+regionid)
+			}
+			httpr.Body.Close()
+			tracked.queues = append(tracked.queues, map[string]interface{}{
+				"region_id":            regionid,
+				"queue_id":             q.GetQueueId(),
+				"dead_letter_queue_id": "",
+			})
+		}
+		for _, q := range queues {
+			dlq, ok := q.GetDeadLetterQueueIdOk()
+			if !ok || *dlq == "" {
+				continue
+			}
+			body := newENVCloneQueueBody(&q, true)
+			_, httpr, err := pco.amqclient.DefaultApi.UpdateAMQ(authctx, orgid, envid, regionid, q.GetQueueId()).QueueBody(*body).Execute()
+			if err != nil {
+				return envCloneRequestError(httpr, err, "set dead letter queue of queue "+q.GetQueueId()+" in region "+regionid)
+			}
+			httpr.Body.Close()
+			for _, item := range tracked.queues {
+				t := item.(map[string]interface{})
+				if t["region_id"] == regionid && t["queue_id"] == q.GetQueueId() {
+					t["dead_letter_queue_id"] = *dlq
+				}
+			}
+		}
+	}
+	return nil
+}
+
+/*
+Replicates the exchanges of the source environment.
+The bindings to the queues replicated beforehand are replicated along with their routing rules.
+*/
+func cloneENVExchanges(ctx context.Context, pco *ProviderConfOutput, orgid string, sourceid string, envid string, regions []string, tracked *envCloneTracked, warn func(string)) error {
+	authctx := getAMEAuthCtx(ctx, pco)
+	bindingauthctx := getAMEBindingAuthCtx(ctx, pco)
+	for _, regionid := range regions {
+		exchanges, skip, warning, err := listENVCloneDestinations(ctx, pco, orgid, sourceid, regionid, "exchange")
+		if err != nil {
+			return err
+		}
+		if warning != "" {
+			warn(warning)
+		}
+		if skip {
+			continue
+		}
+		queueids := make([]string, 0)
+		for _, item := range tracked.queues {
+			t := item.(map[string]interface{})
+			if t["region_id"] == regionid {
+				queueids = append(queueids, t["queue_id"].(string))
+			}
+		}
+		for _, e := range exchanges {
+			exchangeid := e.GetExchangeId()
+			body := new(ame.ExchangeBody)
+			body.SetEncrypted(e.GetEncrypted())
+			_, httpr, err := pco.ameclient.DefaultApi.CreateAME(authctx, orgid, envid, regionid, exchangeid).ExchangeBody(*body).Execute()
+			if err != nil {
+				return envCloneRequestError(httpr, err, "create exchange "+exchangeid+" in region "+regionid)
+			}
+			httpr.Body.Close()
+			tracked.exchanges = append(tracked.exchanges, map[string]interface{}{
+				"region_id":   regionid,
+				"exchange_id": exchangeid,
+			})
+
+			for _, queueid := range queueids {
+				binding, httpr, err := pco.amebindingclient.DefaultApi.GetAMEBinding(bindingauthctx, orgid, sourceid, regionid, exchangeid, queueid).Inclusion("ALL").Execute()
+				if err != nil {
+					if httpr != nil && httpr.StatusCode == http.StatusNotFound {
+						continue
+					}
+					return envCloneRequestError(httpr, err, "get binding of exchange "+exchangeid+" to queue "+queueid+" in region "+regionid)
+				}
+				httpr.Body.Close()
+				_, httpr, err = pco.amebindingclient.DefaultApi.CreateAMEBinding(bindingauthctx, orgid, envid, regionid, exchangeid, queueid).Execute()
+				if err != nil {
+					return envCloneRequestError(httpr, err, "bind exchange "+exchangeid+" to queue "+queueid+" in region "+regionid)
+				}
+				httpr.Body.Close()
+				tracked.bindings = append(tracked.bindings, map[string]interface{}{
+					"region_id":   regionid,
+					"exchange_id": exchangeid,
+					"queue_id":    queueid,
+				})
+				rules := parseAMERBindingRules(binding)
+				if len(rules) == 0 {
+					continue
+				}
+				list := make([]map[string]interface{}, len(rules))
+				for i, rule := range rules {
+					list[i] = map[string]interface{}{
+						"propertyName": rule["property_name"],
+						"propertyType": rule["property_type"],
+						"matcherType":  rule["matcher_type"],
+						"value":        rule["value"],
+					}
+				}
+				rulesbody := ame_binding.NewAMEBindingRuleBody()
+				rulesbody.SetRoutingRules(list)
+				_, httpr, err = pco.amebindingclient.DefaultApi.CreateAMEBindingRule(bindingauthctx, orgid, envid, regionid, exchangeid, queueid).AMEBindingRuleBody(*rulesbody).Execute()
+				if err != nil {
+					return envCloneRequestError(httpr, err, "create routing rules of binding of exchange "+exchangeid+" to queue "+queueid+" in region "+regionid)
+				}
+				httpr.Body.Close()
+			}
+		}
+	}
+	return nil
+}
+
+// associates the cloned environment to the VPCs the source environment is associated to, except the skipped ones
+func cloneENVVpcAssociations(ctx context.Context, pco *ProviderConfOutput, orgid string, sourceid string, envid string, skipped []string, tracked *envCloneTracked) error {
+	authctx := getVPCAuthCtx(ctx, pco)
+	res, httpr, err := pco.vpcclient.DefaultApi.OrganizationsOrgIdVpcsGet(authctx, orgid).Execute()
+	if err != nil {
+		return envCloneRequestError(httpr, err, "get VPCs")
+	}
+	httpr.Body.Close()
+	for _, v := range res.GetData() {
+		if !StringInSlice(v.GetAssociatedEnvironments(), sourceid, false) || StringInSlice(skipped, v.GetId(), false) {
+			continue
+		}
+		err := modifyVPCAssociatedEnvironments(ctx, pco, orgid, v.GetId(), func(envs []string) []string {
+			if StringInSlice(envs, envid, false) {
+				return envs
+			}
+			return append(envs, envid)
+		})
+		if err != nil {
+			return err
+		}
+		tracked.vpcIds = append(tracked.vpcIds, v.GetId())
+	}
+	return nil
+}
+
+// grants the roles scoped to the source environment to the same teams on the cloned environment
+func cloneENVTeamRoles(ctx context.Context, pco *ProviderConfOutput, orgid string, sourceid string, envid string, tracked *envCloneTracked) error {
+	// teams are defined in the master organization
+	bg, err := getOrg(getBGAuthCtx(ctx, pco), pco, orgid)
+	if err != nil {
+		return err
+	}
+	masterid := getBGMasterId(bg)
+
+	teamauthctx := getTeamAuthCtx(ctx, pco)
+	rolesauthctx := getTeamRolesAuthCtx(ctx, pco)
+	offset, limit := 0, 200
+	for {
+		res, httpr, err := pco.teamclient.DefaultApi.OrganizationsOrgIdTeamsGet(teamauthctx, masterid).Offset(int32(offset)).Limit(int32(limit)).Execute()
+		if err != nil {
+			return envCloneRequestError(httpr, err, "get teams")
+		}
+		httpr.Body.Close()
+		page := res.GetData()
+		for _, t := range page {
+			teamid := t.GetTeamId()
+			body := make([]map[string]interface{}, 0)
+			rolesoffset, roleslimit := 0, 500
+			for {
+				roles, httpr, err := pco.teamrolesclient.DefaultApi.OrganizationsOrgIdTeamsTeamIdRolesGet(rolesauthctx, masterid, teamid).Offset(int32(rolesoffset)).Limit(int32(roleslimit)).Execute()
+				if err != nil {
+					return envCloneRequestError(httpr, err, "get roles of team "+teamid)
+				}
+				httpr.Body.Close()
+				rolespage := roles.GetData()
+				for _, role := range rolespage {
+					params := role.GetContextParams()
+					if e, ok := params.GetEnvIdOk(); !ok || *e != sourceid {
+						continue
+					}
+					body = append(body, map[string]interface{}{
+						"role_id": role.GetRoleId(),
+						"context_params": map[string]interface{}{
+							"org":   orgid,
+							"envId": envid,
+						},
+					})
+				}
+				rolesoffset += len(rolespage)
+				if len(rolespage) < roleslimit || rolesoffset >= int(roles.GetTotal()) {
+					break
+				}
+			}
+			if len(body) == 0 {
+				continue
+			}
+			httpr, err := pco.teamrolesclient.DefaultApi.OrganizationsOrgIdTeamsTeamIdRolesPost(rolesauthctx, masterid, teamid).RequestBody(body).Execute()
+			if err != nil {
+				return envCloneRequestError(httpr, err, "grant roles to team "+teamid)
+			}
+			httpr.Body.Close()
+			for _, item := range body {
+				tracked.teamRoles = append(tracked.teamRoles, map[string]interface{}{
+					"team_org_id": masterid,
+					"team_id":     teamid,
+					"role_id":     item["role_id"],
+				})
+			}
+		}
+		offset += len(page)
+		if len(page) < limit || offset >= int(res.GetTotal()) {
+			break
+		}
+	}
+	return nil
+}
+
+/*
+Removes the replicated objects in the reverse order of their creation.
+The removed objects are dropped from the tracked objects, the objects already removed are ignored.
+*/
+func deleteENVCloneTracked(ctx context.Context, pco *ProviderConfOutput, orgid string, envid string, tracked *envCloneTracked) error {
+	//team roles
+	rolesauthctx := getTeamRolesAuthCtx(ctx, pco)
+	for len(tracked.teamRoles) > 0 {
+		t := tracked.teamRoles[0].(map[string]interface{})
+		body := []map[string]interface{}{
+			{
+				"role_id": t["role_id"],
+				"context_params": map[string]interface{}{
+					"org":   orgid,
+					"envId": envid,
+				},
+			},
+		}
+		httpr, err := pco.teamrolesclient.DefaultApi.OrganizationsOrgIdTeamsTeamIdRolesDelete(rolesauthctx, t["team_org_id"].(string), t["team_id"].(string)).RequestBody(body).Execute()
+		if err != nil && !(httpr != nil && httpr.StatusCode == http.StatusNotFound) {
+			return envCloneRequestError(httpr, err, fmt.Sprintf("revoke role %s from team %s", t["role_id"], t["team_id"]))
+		}
+		if httpr != nil {
+			httpr.Body.Close()
+		}
+		tracked.teamRoles = tracked.teamRoles[1:]
+	}
+
+	//VPC associations
+	for len(tracked.vpcIds) > 0 {
+		vpcid := tracked.vpcIds[0]
+		err := modifyVPCAssociatedEnvironments(ctx, pco, orgid, vpcid, func(envs []string) []string {
+			res := make([]string, 0, len(envs))
+			for _, e := range envs {
+				if e != envid {
+					res = append(res, e)
+				}
+			}
+			return res
+		})
+		if err != nil {
+			return err
+		}
+		tracked.vpcIds = tracked.vpcIds[1:]
+	}
+
+	//bindings
+	bindingauthctx := getAMEBindingAuthCtx(ctx, pco)
+	for len(tracked.bindings) > 0 {
+		t := tracked.bindings[0].(map[string]interface{})
+		regionid, exchangeid, queueid := t["region_id"].(string), t["exchange_id"].(string), t["queue_id"].(string)
+		httpr, err := pco.amebindingclient.DefaultApi.DeleteAMEBinding(bindingauthctx, orgid, envid, regionid, exchangeid, queueid).Execute()
+		if err != nil && !(httpr != nil && httpr.StatusCode == http.StatusNotFound) {
+			return envCloneRequestError(httpr, err, "delete binding of exchange "+exchangeid+" to queue "+queueid+" in region "+regionid)
+		}
+		if httpr != nil {
+			httpr.Body.Close()
+		}
+		tracked.bindings = tracked.bindings[1:]
+	}
+
+	//exchanges
+	ameauthctx := getAMEAuthCtx(ctx, pco)
+	for len(tracked.exchanges) > 0 {
+		t := tracked.exchanges[0].(map[string]interface{})
+		regionid, exchangeid := t["region_id"].(string), t["exchange_id"].(string)
+		httpr, err := pco.ameclient.DefaultApi.DeleteAME(ameauthctx, orgid, envid, regionid, exchangeid).Execute()
+		if err != nil && !(httpr != nil && httpr.StatusCode == http.StatusNotFound) {
+			return envCloneRequestError(httpr, err, "delete exchange "+exchangeid+" in region "+regionid)
+		}
+		if httpr != nil {
+			httpr.Body.Close()
+		}
+		tracked.exchanges = tracked.exchanges[1:]
+	}
+
+	//queues, the ones used as dead letter queues are deleted last
+	amqauthctx := getAMQAuthCtx(ctx, pco)
+	for len(tracked.queues) > 0 {
+		next := 0
+		for i, item := range tracked.queues {
+			if !isENVCloneDeadLetterQueue(tracked.queues, item.(map[string]interface{})) {
+				next = i
+				break
+			}
+		}
+		t := tracked.queues[next].(map[string]interface{})
+		regionid, queueid := t["region_id"].(string), t["queue_id"].(string)
+		httpr, err := pco.amqclient.DefaultApi.DeleteAMQ(amqauthctx, orgid, envid, regionid, queueid).Execute()
+		if err != nil && !(httpr != nil && httpr.StatusCode == http.StatusNotFound) {
+			return envCloneRequestError(httpr, err, "delete queue "+queueid+" in region "+regionid)
+		}
+		if httpr != nil {
+			httpr.Body.Close()
+		}
+		tracked.queues = append(tracked.queues[:next], tracked.queues[next+1:]...)
+	}
+
+	return nil
+}
+
+// true if the given queue is the dead letter queue of another tracked queue
+func isENVCloneDeadLetterQueue(queues []interface{}, queue map[string]interface{}) bool {
+	for _, item := range queues {
+		q := item.(map[string]interface{})
+		if q["region_id"] == queue["region_id"] && q["dead_letter_queue_id"] == queue["queue_id"] && q["queue_id"] != queue["queue_id"] {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+Lists all the destinations of the given type in the environment's region.
+Returns skip set to true when MQ is not enabled in the region,
+along with a warning when the region is skipped because the destinations are not readable with the current credentials.
+*/
+func listENVCloneDestinations(ctx context.Context, pco *ProviderConfOutput, orgid string, envid string, regionid string, desttype string) ([]amq.Queue, bool, string, error) {
+	authctx := getAMQAuthCtx(ctx, pco)
+	req := pco.amqclient.DefaultApi.GetAMQList(authctx, orgid, envid, regionid).Inclusion("ALL").DestinationType(desttype)
+	res := make([]amq.Queue, 0)
+	offset, limit := 0, ENV_CLONE_DESTINATIONS_PAGE_SIZE
+	for {
+		page, httpr, err := req.Offset(int32(offset)).Limit(int32(limit)).Execute()
+		if err != nil {
+			if httpr == nil || offset > 0 {
+				return nil, false, "", envCloneRequestError(httpr, err, "list "+desttype+"s in region "+regionid)
+			}
+			b, _ := ioutil.ReadAll(httpr.Body)
+			httpr.Body.Close()
+			if isMQRegionNotEnabled(httpr.StatusCode, string(b)) {
+				return nil, true, "", nil
+			}
+			if httpr.StatusCode == http.StatusForbidden {
+				return nil, true, "MQ " + desttype + "s in region " + regionid + " were skipped: " + string(b), nil
+			}
+			return nil, false, "", fmt.Errorf("unable to list %ss in region %s\n details: %s", desttype, regionid, string(b))
+		}
+		httpr.Body.Close()
+		res = append(res, page...)
+		offset += len(page)
+		// the destinations list does not provide the total number of results
+		if len(page) < limit {
+			break
+		}
+	}
+	return res, false, "", nil
+}
+
+// creates the body of a replicated queue from the source queue, with or without its dead letter queue
+func newENVCloneQueueBody(q *amq.Queue, withdlq bool) *amq.QueueBody {
+	body := new(amq.QueueBody)
+
+	body.SetType("queue")
+	body.SetDefaultTtl(q.GetDefaultTtl())
+	body.SetDefaultLockTtl(q.GetDefaultLockTtl())
+	body.SetDefaultDeliveryDelay(q.GetDefaultDeliveryDelay())
+	body.SetEncrypted(q.GetEncrypted())
+	body.SetFifo(q.GetFifo())
+	if v, ok := q.GetDeadLetterQueueIdOk(); withdlq && ok && *v != "" {
+		body.SetDeadLetterQueueId(*v)
+		body.SetMaxDeliveries(q.GetMaxDeliveries())
+	}
+
+	return body
+}
+
+/*
+Performs a read-modify-write of the VPC's associated environments.
+The VPC is replaced as a whole by the platform, so its other attributes are read and written back.
+*/
+func modifyVPCAssociatedEnvironments(ctx context.Context, pco *ProviderConfOutput, orgid string, vpcid string, modify func([]string) []string) error {
+	l, _ := vpcAssociationLocks.LoadOrStore(vpcid, &sync.Mutex{})
+	lock := l.(*sync.Mutex)
+	lock.Lock()
+	defer lock.Unlock()
+
+	authctx := getVPCAuthCtx(ctx, pco)
+	res, httpr, err := pco.vpcclient.DefaultApi.OrganizationsOrgIdVpcsVpcIdGet(authctx, orgid, vpcid).Execute()
+	if err != nil {
+		if httpr != nil && httpr.StatusCode == http.StatusNotFound {
+			return nil
+		}
+		return envCloneRequestError(httpr, err, "get VPC "+vpcid)
+	}
+	httpr.Body.Close()
+
+	body := vpc.NewVpcCoreWithDefaults()
+	body.SetName(res.GetName())
+	body.SetRegion(res.GetRegion())
+	body.SetCidrBlock(res.GetCidrBlock())
+	body.SetIsDefault(res.GetIsDefault())
+	body.SetOwnerId(res.GetOwnerId())
+	body.SetSharedWith(res.GetSharedWith())
+	body.SetAssociatedEnvironments(modify(res.GetAssociatedEnvironments()))
+	body.SetInternalDns(res.GetInternalDns())
+	body.SetFirewallRules(res.GetFirewallRules())
+
+	_, httpr, err = pco.vpcclient.DefaultApi.OrganizationsOrgIdVpcsVpcIdPut(authctx, orgid, vpcid).VpcCore(*body).Execute()
+	if err != nil {
+		return envCloneRequestError(httpr, err, "update VPC "+vpcid+" associated environments")
+	}
+	httpr.Body.Close()
+	return nil
+}
+
+func envCloneRequestError(httpr *http.Response, err error, action string) error {
+	var details string
+	if httpr != nil {
+		b, _ := ioutil.ReadAll(httpr.Body)
+		httpr.Body.Close()
+		details = string(b)
+	} else {
+		details = err.Error()
+	}
+	return fmt.Errorf("unable to %s\n details: %s", action, details)
+}
+
+func getENVCloneTrackedFromResourceData(d *schema.ResourceData) *envCloneTracked {
+	return &envCloneTracked{
+		queues:    d.Get("queues").([]interface{}),
+		exchanges: d.Get("exchanges").([]interface{}),
+		bindings:  d.Get("bindings").([]interface{}),
+		vpcIds:    ListInterface2ListStrings(d.Get("vpc_ids").([]interface{})),
+		teamRoles: d.Get("team_roles").([]interface{}),
+	}
+}
+
+func setENVCloneTrackedToResourceData(d *schema.ResourceData, tracked *envCloneTracked) {
+	d.Set("queues", tracked.queues)
+	d.Set("exchanges", tracked.exchanges)
+	d.Set("bindings", tracked.bindings)
+	d.Set("vpc_ids", tracked.vpcIds)
+	d.Set("team_roles", tracked.teamRoles)
+}
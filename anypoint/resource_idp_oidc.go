@@ -2,7 +2,11 @@ package anypoint
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"io/ioutil"
+	"net/http"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
@@ -16,8 +20,10 @@ func resourceOIDC() *schema.Resource {
 		ReadContext:   resourceOIDCRead,
 		UpdateContext: resourceOIDCUpdate,
 		DeleteContext: resourceOIDCDelete,
+		CustomizeDiff: resourceOIDCCustomizeDiff,
 		Description: `
 		Creates an ` + "`" + `identity provider` + "`" + ` OIDC type configuration in your account.
+		When ` + "`" + `issuer_discovery` + "`" + ` is enabled, the provider's endpoints are loaded from the issuer's discovery document (` + "`" + `/.well-known/openid-configuration` + "`" + `).
 		`,
 		Schema: map[string]*schema.Schema{
 			"last_updated": {
@@ -59,8 +65,8 @@ func resourceOIDC() *schema.Resource {
 					Schema: map[string]*schema.Schema{
 						"token_url": {
 							Type:        schema.TypeString,
-							Required:    true,
-							Description: "The token url of the openid-connect provider",
+							Optional:    true,
+							Description: "The token url of the openid-connect provider. Required unless issuer_discovery is enabled.",
 						},
 						"redirect_url": {
 							Type:        schema.TypeString,
@@ -69,18 +75,18 @@ func resourceOIDC() *schema.Resource {
 						},
 						"userinfo_url": {
 							Type:        schema.TypeString,
-							Required:    true,
-							Description: "The userinfo url of the openid-connect provider",
+							Optional:    true,
+							Description: "The userinfo url of the openid-connect provider. Required unless issuer_discovery is enabled.",
 						},
 						"authorize_url": {
 							Type:        schema.TypeString,
-							Required:    true,
-							Description: "The authorization url of the openid-connect provider",
+							Optional:    true,
+							Description: "The authorization url of the openid-connect provider. Required unless issuer_discovery is enabled.",
 						},
 						"client_registration_url": {
 							Type:        schema.TypeString,
 							Optional:    true,
-							Description: "The registration url, for dynamic client registration, of the openid-connect provider. Mutually exclusive with credentials id/secret, if both are given registration url is prioritized.",
+							Description: "The registration url, for dynamic client registration, of the openid-connect provider. Mutually exclusive with credentials id/secret, if both are given registration url is prioritized. When issuer_discovery is enabled and no credentials id is given, defaults to the provider's registration endpoint.",
 						},
 						"client_credentials_id": {
 							Type:        schema.TypeString,
@@ -95,8 +101,9 @@ func resourceOIDC() *schema.Resource {
 						},
 						"client_token_endpoint_auth_methods_supported": {
 							Type:        schema.TypeList,
+							Optional:    true,
 							Computed:    true,
-							Description: "The list of authentication methods supported",
+							Description: "The list of authentication methods supported. When issuer_discovery is enabled, the methods must be offered by the provider's token endpoint and default to the ones it offers.",
 							Elem: &schema.Schema{
 								Type: schema.TypeString,
 							},
@@ -130,6 +137,54 @@ func resourceOIDC() *schema.Resource {
 				Computed:    true,
 				Description: "The provider's sign out url, only available for SAML",
 			},
+			"issuer_discovery": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "If true, the endpoints not set explicitly are loaded from the issuer's discovery document. The document is fetched at plan time, changes of the document are reported as drift.",
+			},
+			"discovery": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The values loaded from the issuer's discovery document when issuer_discovery is enabled.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"issuer": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The issuer declared in the discovery document.",
+						},
+						"authorization_endpoint": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The authorization url of the openid-connect provider.",
+						},
+						"token_endpoint": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The token url of the openid-connect provider.",
+						},
+						"userinfo_endpoint": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The userinfo url of the openid-connect provider.",
+						},
+						"registration_endpoint": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The dynamic client registration url of the openid-connect provider.",
+						},
+						"token_endpoint_auth_methods_supported": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "The authentication methods supported by the token endpoint.",
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+					},
+				},
+			},
 		},
 	}
 }
@@ -140,7 +195,12 @@ func resourceOIDCCreate(ctx context.Context, d *schema.ResourceData, m interface
 	orgid := d.Get("org_id").(string)
 
 	authctx := getIDPAuthCtx(ctx, &pco)
-	body, errDiags := newOIDCPostBody(d)
+	discovery, errDiags := loadOIDCDiscoveryToResourceData(ctx, d)
+	if errDiags.HasError() {
+		diags = append(diags, errDiags...)
+		return diags
+	}
+	body, errDiags := newOIDCPostBody(d, discovery)
 	if errDiags.HasError() {
 		diags = append(diags, errDiags...)
 		return diags
@@ -196,6 +256,7 @@ func resourceOIDCRead(ctx context.Context, d *schema.ResourceData, m interface{}
 	}
 	//process data
 	idpinstance := flattenIDPData(&res)
+	keepOIDCDiscoveryManagedAttributes(d, idpinstance)
 	//save in data source schema
 	if err := setIDPAttributesToResourceData(d, idpinstance); err != nil {
 		diags := append(diags, diag.Diagnostic{
@@ -215,9 +276,14 @@ func resourceOIDCUpdate(ctx context.Context, d *schema.ResourceData, m interface
 	idpid := d.Id()
 	orgid := d.Get("org_id").(string)
 
-	if d.HasChanges(getIDPAttributes()...) {
+	if d.HasChanges(getIDPAttributes()...) || d.HasChanges("issuer_discovery", "discovery") {
 		authctx := getIDPAuthCtx(ctx, &pco)
-		body, errDiags := newOIDCPatchBody(d)
+		discovery, errDiags := loadOIDCDiscoveryToResourceData(ctx, d)
+		if errDiags.HasError() {
+			diags = append(diags, errDiags...)
+			return diags
+		}
+		body, errDiags := newOIDCPatchBody(d, discovery)
 		if errDiags.HasError() {
 			diags = append(diags, errDiags...)
 			return diags
@@ -278,7 +344,7 @@ func resourceOIDCDelete(ctx context.Context, d *schema.ResourceData, m interface
 }
 
 /* Prepares the body required to post an OIDC provider*/
-func newOIDCPostBody(d *schema.ResourceData) (*idp.IdpPostBody, diag.Diagnostics) {
+func newOIDCPostBody(d *schema.ResourceData, discovery *oidcDiscovery) (*idp.IdpPostBody, diag.Diagnostics) {
 	var diags diag.Diagnostics
 
 	name := d.Get("name").(string)
@@ -301,8 +367,8 @@ func newOIDCPostBody(d *schema.ResourceData) (*idp.IdpPostBody, diag.Diagnostics
 			// reads client registration or credentials depending on which one is added
 			client := idp.NewClient1()
 			client_urls := idp.NewUrls1()
-			if client_registration_url, ok := data["client_registration_url"]; ok {
-				client_urls.SetRegister(client_registration_url.(string))
+			if client_registration_url := oidcProviderUrl(data, "client_registration_url", discovery); client_registration_url != "" {
+				client_urls.SetRegister(client_registration_url)
 				client.SetUrls(*client_urls)
 			} else {
 				credentials := idp.NewCredentials1()
@@ -314,19 +380,16 @@ func newOIDCPostBody(d *schema.ResourceData) (*idp.IdpPostBody, diag.Diagnostics
 				}
 				client.SetCredentials(*credentials)
 			}
+			if methods := oidcProviderAuthMethods(data, discovery); len(methods) > 0 {
+				client.SetTokenEndpointAuthMethodsSupported(methods)
+			}
 			oidc_provider.SetClient(*client)
 
 			//Parsing URLs
 			urls := idp.NewUrls3()
-			if token_url, ok := data["token_url"]; ok {
-				urls.SetToken(token_url.(string))
-			}
-			if userinfo_url, ok := data["userinfo_url"]; ok {
-				urls.SetUserinfo(userinfo_url.(string))
-			}
-			if authorize_url, ok := data["authorize_url"]; ok {
-				urls.SetAuthorize(authorize_url.(string))
-			}
+			urls.SetToken(oidcProviderUrl(data, "token_url", discovery))
+			urls.SetUserinfo(oidcProviderUrl(data, "userinfo_url", discovery))
+			urls.SetAuthorize(oidcProviderUrl(data, "authorize_url", discovery))
 			oidc_provider.SetUrls(*urls)
 
 			if issuer, ok := data["issuer"]; ok {
@@ -349,7 +412,7 @@ func newOIDCPostBody(d *schema.ResourceData) (*idp.IdpPostBody, diag.Diagnostics
 }
 
 /* Prepares the body required to patch an OIDC provider*/
-func newOIDCPatchBody(d *schema.ResourceData) (*idp.IdpPatchBody, diag.Diagnostics) {
+func newOIDCPatchBody(d *schema.ResourceData, discovery *oidcDiscovery) (*idp.IdpPatchBody, diag.Diagnostics) {
 	var diags diag.Diagnostics
 
 	name := d.Get("name").(string)
@@ -371,8 +434,8 @@ func newOIDCPatchBody(d *schema.ResourceData) (*idp.IdpPatchBody, diag.Diagnosti
 			// reads client registration or credentials depending on which one is added
 			client := idp.NewClient1()
 			client_urls := idp.NewUrls1()
-			if client_registration_url, ok := data["client_registration_url"]; ok {
-				client_urls.SetRegister(client_registration_url.(string))
+			if client_registration_url := oidcProviderUrl(data, "client_registration_url", discovery); client_registration_url != "" {
+				client_urls.SetRegister(client_registration_url)
 				client.SetUrls(*client_urls)
 			} else {
 				credentials := idp.NewCredentials1()
@@ -384,19 +447,16 @@ func newOIDCPatchBody(d *schema.ResourceData) (*idp.IdpPatchBody, diag.Diagnosti
 				}
 				client.SetCredentials(*credentials)
 			}
+			if methods := oidcProviderAuthMethods(data, discovery); len(methods) > 0 {
+				client.SetTokenEndpointAuthMethodsSupported(methods)
+			}
 			oidc_provider.SetClient(*client)
 
 			//Parsing URLs
 			urls := idp.NewUrls3()
-			if token_url, ok := data["token_url"]; ok {
-				urls.SetToken(token_url.(string))
-			}
-			if userinfo_url, ok := data["userinfo_url"]; ok {
-				urls.SetUserinfo(userinfo_url.(string))
-			}
-			if authorize_url, ok := data["authorize_url"]; ok {
-				urls.SetAuthorize(authorize_url.(string))
-			}
+			urls.SetToken(oidcProviderUrl(data, "token_url", discovery))
+			urls.SetUserinfo(oidcProviderUrl(data, "userinfo_url", discovery))
+			urls.SetAuthorize(oidcProviderUrl(data, "authorize_url", discovery))
 			oidc_provider.SetUrls(*urls)
 
 			if issuer, ok := data["issuer"]; ok {
@@ -422,3 +482,196 @@ func getIDPAuthCtx(ctx context.Context, pco *ProviderConfOutput) context.Context
 	tmp := context.WithValue(ctx, idp.ContextAccessToken, pco.access_token)
 	return context.WithValue(tmp, idp.ContextServerIndex, pco.server_index)
 }
+
+// timeout used to fetch the issuer's discovery document
+const OIDC_DISCOVERY_FETCH_TIMEOUT = 30 * time.Second
+
+// the openid-connect discovery document's values used to configure the identity provider
+type oidcDiscovery struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserinfoEndpoint                  string   `json:"userinfo_endpoint"`
+	RegistrationEndpoint              string   `json:"registration_endpoint"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+}
+
+/*
+Fetches the discovery document of the given issuer.
+As per the openid-connect discovery specification, the issuer declared in the document must match the given issuer.
+*/
+func fetchOIDCDiscovery(ctx context.Context, issuer string) (*oidcDiscovery, error) {
+	url := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+	ctx, cancel := context.WithTimeout(ctx, OIDC_DISCOVERY_FETCH_TIMEOUT)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	httpr, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch discovery document from %s: %s", url, err)
+	}
+	defer httpr.Body.Close()
+	b, err := ioutil.ReadAll(httpr.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch discovery document from %s: %s", url, err)
+	}
+	if httpr.StatusCode >= 300 {
+		return nil, fmt.Errorf("unable to fetch discovery document from %s: status %d", url, httpr.StatusCode)
+	}
+	discovery := &oidcDiscovery{}
+	if err := json.Unmarshal(b, discovery); err != nil {
+		return nil, fmt.Errorf("unable to parse discovery document from %s: %s", url, err)
+	}
+	if strings.TrimSuffix(discovery.Issuer, "/") != strings.TrimSuffix(issuer, "/") {
+		return nil, fmt.Errorf("the discovery document's issuer %s does not match the configured issuer %s", discovery.Issuer, issuer)
+	}
+	// default value defined by the openid-connect discovery specification
+	if len(discovery.TokenEndpointAuthMethodsSupported) == 0 {
+		discovery.TokenEndpointAuthMethodsSupported = []string{"client_secret_basic"}
+	}
+	return discovery, nil
+}
+
+// returns the oidc_provider's issuer or an empty string if not set
+func getOIDCProviderIssuer(set *schema.Set) string {
+	for _, item := range set.List() {
+		if issuer, ok := item.(map[string]interface{})["issuer"].(string); ok {
+			return issuer
+		}
+	}
+	return ""
+}
+
+// loads the issuer's discovery document if enabled and saves its values in discovery
+func loadOIDCDiscoveryToResourceData(ctx context.Context, d *schema.ResourceData) (*oidcDiscovery, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	var discovery *oidcDiscovery
+	if d.Get("issuer_discovery").(bool) {
+		var err error
+		discovery, err = fetchOIDCDiscovery(ctx, getOIDCProviderIssuer(d.Get("oidc_provider").(*schema.Set)))
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Unable to load OIDC discovery document",
+				Detail:   err.Error(),
+			})
+			return nil, diags
+		}
+	}
+	if err := d.Set("discovery", flattenOIDCDiscovery(discovery)); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to set OIDC discovery",
+			Detail:   err.Error(),
+		})
+		return nil, diags
+	}
+	return discovery, diags
+}
+
+/*
+Loads the issuer's discovery document at plan time so that changes of the document show up as drift,
+verifies that the endpoints are set when discovery is disabled and
+that the configured authentication methods are offered by the provider.
+*/
+func resourceOIDCCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("issuer_discovery") || !d.NewValueKnown("oidc_provider") {
+		return d.SetNewComputed("discovery")
+	}
+	list := d.Get("oidc_provider").(*schema.Set).List()
+	if !d.Get("issuer_discovery").(bool) {
+		for _, item := range list {
+			data := item.(map[string]interface{})
+			for _, attr := range []string{"token_url", "userinfo_url", "authorize_url"} {
+				if v, _ := data[attr].(string); v == "" {
+					return fmt.Errorf("oidc_provider.%s is required when issuer_discovery is disabled", attr)
+				}
+			}
+		}
+		return d.SetNew("discovery", []interface{}{})
+	}
+
+	discovery, err := fetchOIDCDiscovery(ctx, getOIDCProviderIssuer(d.Get("oidc_provider").(*schema.Set)))
+	if err != nil {
+		return err
+	}
+	for _, item := range list {
+		methods, _ := item.(map[string]interface{})["client_token_endpoint_auth_methods_supported"].([]interface{})
+		for _, method := range methods {
+			if !StringInSlice(discovery.TokenEndpointAuthMethodsSupported, method.(string), false) {
+				return fmt.Errorf("the authentication method %s is not supported by the provider's token endpoint, supported methods are: %s", method, strings.Join(discovery.TokenEndpointAuthMethodsSupported, ", "))
+			}
+		}
+	}
+	return d.SetNew("discovery", flattenOIDCDiscovery(discovery))
+}
+
+/*
+Keeps the endpoints and registration url loaded from the discovery document empty in the state
+when they are not set explicitly, their drift is detected through discovery.
+*/
+func keepOIDCDiscoveryManagedAttributes(d *schema.ResourceData, idpitem map[string]interface{}) {
+	if idpitem == nil || !d.Get("issuer_discovery").(bool) {
+		return
+	}
+	current := d.Get("oidc_provider").(*schema.Set).List()
+	remote, ok := idpitem["oidc_provider"].([]interface{})
+	if len(current) == 0 || !ok || len(remote) == 0 {
+		return
+	}
+	c := current[0].(map[string]interface{})
+	r := remote[0].(map[string]interface{})
+	for _, attr := range []string{"token_url", "userinfo_url", "authorize_url", "client_registration_url"} {
+		if v, _ := c[attr].(string); v == "" {
+			r[attr] = ""
+		}
+	}
+}
+
+// returns the url explicitly set in the given oidc_provider data, or the discovered one
+func oidcProviderUrl(data map[string]interface{}, attr string, discovery *oidcDiscovery) string {
+	if v, _ := data[attr].(string); v != "" || discovery == nil {
+		return v
+	}
+	switch attr {
+	case "token_url":
+		return discovery.TokenEndpoint
+	case "userinfo_url":
+		return discovery.UserinfoEndpoint
+	case "authorize_url":
+		return discovery.AuthorizationEndpoint
+	case "client_registration_url":
+		// manual registration takes precedence over the discovered registration endpoint
+		if id, _ := data["client_credentials_id"].(string); id != "" {
+			return ""
+		}
+		return discovery.RegistrationEndpoint
+	}
+	return ""
+}
+
+// returns the authentication methods explicitly set in the given oidc_provider data, or the discovered ones
+func oidcProviderAuthMethods(data map[string]interface{}, discovery *oidcDiscovery) []string {
+	if methods, _ := data["client_token_endpoint_auth_methods_supported"].([]interface{}); len(methods) > 0 || discovery == nil {
+		return ListInterface2ListStrings(methods)
+	}
+	return discovery.TokenEndpointAuthMethodsSupported
+}
+
+func flattenOIDCDiscovery(discovery *oidcDiscovery) []interface{} {
+	if discovery == nil {
+		return []interface{}{}
+	}
+	item := map[string]interface{}{
+		"issuer":                                discovery.Issuer,
+		"authorization_endpoint":                discovery.AuthorizationEndpoint,
+		"token_endpoint":                        discovery.TokenEndpoint,
+		"userinfo_endpoint":                     discovery.UserinfoEndpoint,
+		"registration_endpoint":                 discovery.RegistrationEndpoint,
+		"token_endpoint_auth_methods_supported": discovery.TokenEndpointAuthMethodsSupported,
+	}
+	return []interface{}{item}
+}
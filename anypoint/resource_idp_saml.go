@@ -2,11 +2,19 @@ package anypoint
 
 import (
 	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"encoding/xml"
+	"fmt"
 	"io/ioutil"
+	"net/http"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/mulesoft-anypoint/anypoint-client-go/idp"
 )
 
@@ -16,8 +24,11 @@ func resourceSAML() *schema.Resource {
 		ReadContext:   resourceSAMLRead,
 		UpdateContext: resourceSAMLUpdate,
 		DeleteContext: resourceSAMLDelete,
+		CustomizeDiff: resourceSAMLCustomizeDiff,
 		Description: `
 		Creates an ` + "`" + `identity provider` + "`" + ` SAML type configuration in your account.
+		The issuer, signing certificates and sign on/out urls can be loaded from the identity provider's federation metadata using either ` + "`" + `metadata_xml` + "`" + ` or ` + "`" + `metadata_url` + "`" + `.
+		Values set explicitly take precedence over the metadata.
 		`,
 		Schema: map[string]*schema.Schema{
 			"last_updated": {
@@ -59,8 +70,8 @@ func resourceSAML() *schema.Resource {
 					Schema: map[string]*schema.Schema{
 						"issuer": {
 							Type:        schema.TypeString,
-							Required:    true,
-							Description: "The provider issuer",
+							Optional:    true,
+							Description: "The provider issuer. Required unless the metadata is provided.",
 						},
 						"audience": {
 							Type:        schema.TypeString,
@@ -69,8 +80,8 @@ func resourceSAML() *schema.Resource {
 						},
 						"public_key": {
 							Type:        schema.TypeList,
-							Required:    true,
-							Description: "The list of public keys",
+							Optional:    true,
+							Description: "The list of public keys. Required unless the metadata is provided.",
 							Elem: &schema.Schema{
 								Type: schema.TypeString,
 							},
@@ -128,13 +139,75 @@ func resourceSAML() *schema.Resource {
 			},
 			"sp_sign_on_url": {
 				Type:        schema.TypeString,
-				Required:    true,
-				Description: "The identity provider's sign on url",
+				Optional:    true,
+				Description: "The identity provider's sign on url. Required unless the metadata is provided.",
 			},
 			"sp_sign_out_url": {
 				Type:        schema.TypeString,
-				Required:    true,
-				Description: "The identity provider's sign out url, only available for SAML",
+				Optional:    true,
+				Description: "The identity provider's sign out url, only available for SAML. Required unless the metadata is provided.",
+			},
+			"metadata_xml": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ConflictsWith:    []string{"metadata_url"},
+				Description:      "The identity provider's federation metadata XML document.",
+				ValidateDiagFunc: validation.ToDiagFunc(validateSAMLMetadataXML),
+			},
+			"metadata_url": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ConflictsWith:    []string{"metadata_xml"},
+				Description:      "The url of the identity provider's federation metadata. The metadata is fetched at plan time, changes of the metadata are reported as drift.",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IsURLWithScheme([]string{"http", "https"})),
+			},
+			"parsed_metadata": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The values parsed from the identity provider's federation metadata.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"entity_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The entity id of the identity provider, used as issuer.",
+						},
+						"sso_url": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The single sign on service url.",
+						},
+						"slo_url": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The single logout service url.",
+						},
+						"signing_certificates": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "The signing certificates of the identity provider.",
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"certificate": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "The PEM encoded certificate.",
+									},
+									"subject": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "The subject of the certificate.",
+									},
+									"not_after": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "The expiration date of the certificate (RFC3339 format).",
+									},
+								},
+							},
+						},
+					},
+				},
 			},
 		},
 	}
@@ -146,7 +219,12 @@ func resourceSAMLCreate(ctx context.Context, d *schema.ResourceData, m interface
 	orgid := d.Get("org_id").(string)
 
 	authctx := getIDPAuthCtx(ctx, &pco)
-	body, errDiags := newSAMLPostBody(d)
+	metadata, errDiags := loadSAMLMetadataToResourceData(ctx, d)
+	if errDiags.HasError() {
+		diags = append(diags, errDiags...)
+		return diags
+	}
+	body, errDiags := newSAMLPostBody(d, metadata)
 	if errDiags.HasError() {
 		diags = append(diags, errDiags...)
 		return diags
@@ -202,6 +280,7 @@ func resourceSAMLRead(ctx context.Context, d *schema.ResourceData, m interface{}
 	}
 	//process data
 	idpinstance := flattenIDPData(&res)
+	keepSAMLMetadataManagedAttributes(d, idpinstance)
 	//save in data source schema
 	if err := setIDPAttributesToResourceData(d, idpinstance); err != nil {
 		diags := append(diags, diag.Diagnostic{
@@ -211,6 +290,7 @@ func resourceSAMLRead(ctx context.Context, d *schema.ResourceData, m interface{}
 		})
 		return diags
 	}
+	diags = append(diags, checkSAMLCertificatesExpiry(d.Get("parsed_metadata").([]interface{}))...)
 
 	return diags
 }
@@ -221,9 +301,14 @@ func resourceSAMLUpdate(ctx context.Context, d *schema.ResourceData, m interface
 	idpid := d.Id()
 	orgid := d.Get("org_id").(string)
 
-	if d.HasChanges(getIDPAttributes()...) {
+	if d.HasChanges(getIDPAttributes()...) || d.HasChanges("metadata_xml", "metadata_url", "parsed_metadata") {
 		authctx := getIDPAuthCtx(ctx, &pco)
-		body, errDiags := newSAMLPatchBody(d)
+		metadata, errDiags := loadSAMLMetadataToResourceData(ctx, d)
+		if errDiags.HasError() {
+			diags = append(diags, errDiags...)
+			return diags
+		}
+		body, errDiags := newSAMLPatchBody(d, metadata)
 		if errDiags.HasError() {
 			diags = append(diags, errDiags...)
 			return diags
@@ -284,13 +369,19 @@ func resourceSAMLDelete(ctx context.Context, d *schema.ResourceData, m interface
 }
 
 /* Prepares the body required to post an OIDC provider*/
-func newSAMLPostBody(d *schema.ResourceData) (*idp.IdpPostBody, diag.Diagnostics) {
+func newSAMLPostBody(d *schema.ResourceData, metadata *samlMetadata) (*idp.IdpPostBody, diag.Diagnostics) {
 	var diags diag.Diagnostics
 
 	name := d.Get("name").(string)
 	saml_input := d.Get("saml")
 	sp_sign_on_url := d.Get("sp_sign_on_url").(string)
 	sp_sign_out_url := d.Get("sp_sign_out_url").(string)
+	if metadata != nil && sp_sign_on_url == "" {
+		sp_sign_on_url = metadata.ssoURL()
+	}
+	if metadata != nil && sp_sign_out_url == "" {
+		sp_sign_out_url = metadata.sloURL()
+	}
 
 	body := idp.NewIdpPostBody()
 
@@ -306,19 +397,23 @@ func newSAMLPostBody(d *schema.ResourceData) (*idp.IdpPostBody, diag.Diagnostics
 		if set.Len() > 0 {
 			item := list[0]
 			data := item.(map[string]interface{})
-			if issuer, ok := data["issuer"]; ok {
+			if issuer, ok := data["issuer"]; ok && issuer.(string) != "" {
 				saml.SetIssuer(issuer.(string))
+			} else if metadata != nil {
+				saml.SetIssuer(metadata.EntityID)
 			}
 			if audience, ok := data["audience"]; ok {
 				saml.SetAudience(audience.(string))
 			}
-			if public_key, ok := data["public_key"]; ok {
+			if public_key, ok := data["public_key"]; ok && len(public_key.([]interface{})) > 0 {
 				l := public_key.([]interface{})
 				keys := make([]string, len(l))
 				for i, k := range l {
 					keys[i] = k.(string)
 				}
 				saml.SetPublicKey(keys)
+			} else if metadata != nil {
+				saml.SetPublicKey(metadata.signingCertificatesPEM())
 			}
 			//parsing claims
 			claims := idp.NewClaimsMapping2()
@@ -365,13 +460,19 @@ func newSAMLPostBody(d *schema.ResourceData) (*idp.IdpPostBody, diag.Diagnostics
 }
 
 /* Prepares the body required to patch an OIDC provider*/
-func newSAMLPatchBody(d *schema.ResourceData) (*idp.IdpPatchBody, diag.Diagnostics) {
+func newSAMLPatchBody(d *schema.ResourceData, metadata *samlMetadata) (*idp.IdpPatchBody, diag.Diagnostics) {
 	var diags diag.Diagnostics
 
 	name := d.Get("name").(string)
 	saml_input := d.Get("saml")
 	sp_sign_on_url := d.Get("sp_sign_on_url").(string)
 	sp_sign_out_url := d.Get("sp_sign_out_url").(string)
+	if metadata != nil && sp_sign_on_url == "" {
+		sp_sign_on_url = metadata.ssoURL()
+	}
+	if metadata != nil && sp_sign_out_url == "" {
+		sp_sign_out_url = metadata.sloURL()
+	}
 
 	body := idp.NewIdpPatchBody()
 
@@ -386,19 +487,23 @@ func newSAMLPatchBody(d *schema.ResourceData) (*idp.IdpPatchBody, diag.Diagnosti
 		if set.Len() > 0 {
 			item := list[0]
 			data := item.(map[string]interface{})
-			if issuer, ok := data["issuer"]; ok {
+			if issuer, ok := data["issuer"]; ok && issuer.(string) != "" {
 				saml.SetIssuer(issuer.(string))
+			} else if metadata != nil {
+				saml.SetIssuer(metadata.EntityID)
 			}
 			if audience, ok := data["audience"]; ok {
 				saml.SetAudience(audience.(string))
 			}
-			if public_key, ok := data["public_key"]; ok {
+			if public_key, ok := data["public_key"]; ok && len(public_key.([]interface{})) > 0 {
 				l := public_key.([]interface{})
 				keys := make([]string, len(l))
 				for i, k := range l {
 					keys[i] = k.(string)
 				}
 				saml.SetPublicKey(keys)
+			} else if metadata != nil {
+				saml.SetPublicKey(metadata.signingCertificatesPEM())
 			}
 			//parsing claims
 			claims := idp.NewClaimsMapping2()
@@ -442,3 +547,303 @@ func newSAMLPatchBody(d *schema.ResourceData) (*idp.IdpPatchBody, diag.Diagnosti
 
 	return body, diags
 }
+
+// the number of days before a signing certificate's expiration from which a warning is raised
+const SAML_CERT_EXPIRY_WARNING_DAYS = 30
+
+// timeout used to fetch the federation metadata from metadata_url
+const SAML_METADATA_FETCH_TIMEOUT = 30 * time.Second
+
+// federation metadata elements used to configure the identity provider, namespaces are ignored
+type samlEntityDescriptor struct {
+	EntityID         string                 `xml:"entityID,attr"`
+	IDPSSODescriptor *samlIDPSSODescriptor  `xml:"IDPSSODescriptor"`
+	Entities         []samlEntityDescriptor `xml:"EntityDescriptor"`
+}
+
+type samlIDPSSODescriptor struct {
+	KeyDescriptors       []samlKeyDescriptor `xml:"KeyDescriptor"`
+	SingleSignOnServices []samlEndpoint      `xml:"SingleSignOnService"`
+	SingleLogoutServices []samlEndpoint      `xml:"SingleLogoutService"`
+}
+
+type samlKeyDescriptor struct {
+	Use              string   `xml:"use,attr"`
+	X509Certificates []string `xml:"KeyInfo>X509Data>X509Certificate"`
+}
+
+type samlEndpoint struct {
+	Binding  string `xml:"Binding,attr"`
+	Location string `xml:"Location,attr"`
+}
+
+// the identity provider's configuration extracted from the federation metadata
+type samlMetadata struct {
+	EntityID     string
+	SSOServices  []samlEndpoint
+	SLOServices  []samlEndpoint
+	Certificates []*x509.Certificate
+}
+
+/*
+Parses the given federation metadata document.
+The document's root may either be an EntityDescriptor or an EntitiesDescriptor,
+in the latter case the first entity describing an identity provider is used.
+*/
+func parseSAMLMetadata(data []byte) (*samlMetadata, error) {
+	var root samlEntityDescriptor
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("unable to parse SAML metadata: %s", err)
+	}
+	entity := &root
+	if entity.IDPSSODescriptor == nil {
+		entity = nil
+		for i := range root.Entities {
+			if root.Entities[i].IDPSSODescriptor != nil {
+				entity = &root.Entities[i]
+				break
+			}
+		}
+	}
+	if entity == nil {
+		return nil, fmt.Errorf("the SAML metadata does not describe any identity provider (IDPSSODescriptor)")
+	}
+	if entity.EntityID == "" {
+		return nil, fmt.Errorf("the SAML metadata does not define the identity provider's entityID")
+	}
+
+	metadata := &samlMetadata{
+		EntityID:    entity.EntityID,
+		SSOServices: entity.IDPSSODescriptor.SingleSignOnServices,
+		SLOServices: entity.IDPSSODescriptor.SingleLogoutServices,
+	}
+	for _, key := range entity.IDPSSODescriptor.KeyDescriptors {
+		// a key descriptor without use is used for both signing and encryption
+		if key.Use != "" && key.Use != "signing" {
+			continue
+		}
+		for _, c := range key.X509Certificates {
+			der, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(c), ""))
+			if err != nil {
+				return nil, fmt.Errorf("unable to decode SAML metadata signing certificate: %s", err)
+			}
+			cert, err := x509.ParseCertificate(der)
+			if err != nil {
+				return nil, fmt.Errorf("unable to parse SAML metadata signing certificate: %s", err)
+			}
+			metadata.Certificates = append(metadata.Certificates, cert)
+		}
+	}
+	if len(metadata.Certificates) == 0 {
+		return nil, fmt.Errorf("the SAML metadata does not define any signing certificate")
+	}
+
+	return metadata, nil
+}
+
+// fetches and parses the federation metadata exposed at the given url
+func fetchSAMLMetadata(ctx context.Context, url string) (*samlMetadata, error) {
+	ctx, cancel := context.WithTimeout(ctx, SAML_METADATA_FETCH_TIMEOUT)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	httpr, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch SAML metadata from %s: %s", url, err)
+	}
+	defer httpr.Body.Close()
+	b, err := ioutil.ReadAll(httpr.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch SAML metadata from %s: %s", url, err)
+	}
+	if httpr.StatusCode >= 300 {
+		return nil, fmt.Errorf("unable to fetch SAML metadata from %s: status %d", url, httpr.StatusCode)
+	}
+	return parseSAMLMetadata(b)
+}
+
+// loads the federation metadata from either the given xml document or url, returns nil if none is given
+func loadSAMLMetadata(ctx context.Context, metadataxml, metadataurl string) (*samlMetadata, error) {
+	if metadataxml != "" {
+		return parseSAMLMetadata([]byte(metadataxml))
+	}
+	if metadataurl != "" {
+		return fetchSAMLMetadata(ctx, metadataurl)
+	}
+	return nil, nil
+}
+
+// loads the federation metadata configured on the resource and saves the parsed values in parsed_metadata
+func loadSAMLMetadataToResourceData(ctx context.Context, d *schema.ResourceData) (*samlMetadata, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	metadata, err := loadSAMLMetadata(ctx, d.Get("metadata_xml").(string), d.Get("metadata_url").(string))
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to load SAML metadata",
+			Detail:   err.Error(),
+		})
+		return nil, diags
+	}
+	if err := d.Set("parsed_metadata", flattenSAMLMetadata(metadata)); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to set SAML parsed metadata",
+			Detail:   err.Error(),
+		})
+		return nil, diags
+	}
+	return metadata, diags
+}
+
+/*
+Parses the federation metadata at plan time so that changes of the metadata show up as drift
+and verifies that the values not provided by the metadata are set.
+*/
+func resourceSAMLCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("metadata_xml") || !d.NewValueKnown("metadata_url") {
+		return d.SetNewComputed("parsed_metadata")
+	}
+	metadata, err := loadSAMLMetadata(ctx, d.Get("metadata_xml").(string), d.Get("metadata_url").(string))
+	if err != nil {
+		return err
+	}
+	if metadata == nil {
+		if d.NewValueKnown("saml") {
+			for _, item := range d.Get("saml").(*schema.Set).List() {
+				data := item.(map[string]interface{})
+				if issuer, _ := data["issuer"].(string); issuer == "" {
+					return fmt.Errorf("saml.issuer is required when neither metadata_xml nor metadata_url are set")
+				}
+				if keys, _ := data["public_key"].([]interface{}); len(keys) == 0 {
+					return fmt.Errorf("saml.public_key is required when neither metadata_xml nor metadata_url are set")
+				}
+			}
+		}
+		for _, attr := range []string{"sp_sign_on_url", "sp_sign_out_url"} {
+			if d.NewValueKnown(attr) && d.Get(attr).(string) == "" {
+				return fmt.Errorf("%s is required when neither metadata_xml nor metadata_url are set", attr)
+			}
+		}
+	}
+	return d.SetNew("parsed_metadata", flattenSAMLMetadata(metadata))
+}
+
+func validateSAMLMetadataXML(i interface{}, k string) ([]string, []error) {
+	v, ok := i.(string)
+	if !ok {
+		return nil, []error{fmt.Errorf("expected type of %s to be string", k)}
+	}
+	if _, err := parseSAMLMetadata([]byte(v)); err != nil {
+		return nil, []error{fmt.Errorf("invalid %s: %s", k, err)}
+	}
+	return nil, nil
+}
+
+/*
+Keeps the attributes loaded from the federation metadata empty in the state
+when they are not set explicitly, their drift is detected through parsed_metadata.
+*/
+func keepSAMLMetadataManagedAttributes(d *schema.ResourceData, idpitem map[string]interface{}) {
+	if idpitem == nil || (d.Get("metadata_xml").(string) == "" && d.Get("metadata_url").(string) == "") {
+		return
+	}
+	for _, attr := range []string{"sp_sign_on_url", "sp_sign_out_url"} {
+		if d.Get(attr).(string) == "" {
+			idpitem[attr] = ""
+		}
+	}
+	current := d.Get("saml").(*schema.Set).List()
+	remote, ok := idpitem["saml"].([]interface{})
+	if len(current) == 0 || !ok || len(remote) == 0 {
+		return
+	}
+	c := current[0].(map[string]interface{})
+	r := remote[0].(map[string]interface{})
+	if issuer, _ := c["issuer"].(string); issuer == "" {
+		r["issuer"] = ""
+	}
+	if keys, _ := c["public_key"].([]interface{}); len(keys) == 0 {
+		r["public_key"] = []string{}
+	}
+}
+
+// returns warnings for the parsed signing certificates expiring soon or already expired
+func checkSAMLCertificatesExpiry(parsed []interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	limit := time.Now().AddDate(0, 0, SAML_CERT_EXPIRY_WARNING_DAYS)
+	for _, p := range parsed {
+		if p == nil {
+			continue
+		}
+		for _, c := range p.(map[string]interface{})["signing_certificates"].([]interface{}) {
+			cert := c.(map[string]interface{})
+			notafter, err := time.Parse(time.RFC3339, cert["not_after"].(string))
+			if err != nil || notafter.After(limit) {
+				continue
+			}
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  "SAML signing certificate expires soon",
+				Detail:   fmt.Sprintf("the identity provider's signing certificate %s expires on %s, update the identity provider's metadata.", cert["subject"], cert["not_after"]),
+			})
+		}
+	}
+	return diags
+}
+
+// returns the PEM encoded signing certificates
+func (metadata *samlMetadata) signingCertificatesPEM() []string {
+	list := make([]string, len(metadata.Certificates))
+	for i, cert := range metadata.Certificates {
+		list[i] = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+	}
+	return list
+}
+
+func (metadata *samlMetadata) ssoURL() string {
+	return selectSAMLEndpoint(metadata.SSOServices)
+}
+
+func (metadata *samlMetadata) sloURL() string {
+	return selectSAMLEndpoint(metadata.SLOServices)
+}
+
+// returns the location of the endpoint with the preferred binding (redirect then post)
+func selectSAMLEndpoint(endpoints []samlEndpoint) string {
+	for _, binding := range []string{"urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect", SAML_BINDING_HTTP_POST} {
+		for _, e := range endpoints {
+			if e.Binding == binding {
+				return e.Location
+			}
+		}
+	}
+	if len(endpoints) > 0 {
+		return endpoints[0].Location
+	}
+	return ""
+}
+
+func flattenSAMLMetadata(metadata *samlMetadata) []interface{} {
+	if metadata == nil {
+		return []interface{}{}
+	}
+	pems := metadata.signingCertificatesPEM()
+	certs := make([]interface{}, len(metadata.Certificates))
+	for i, cert := range metadata.Certificates {
+		certs[i] = map[string]interface{}{
+			"certificate": pems[i],
+			"subject":     cert.Subject.String(),
+			"not_after":   cert.NotAfter.UTC().Format(time.RFC3339),
+		}
+	}
+	item := map[string]interface{}{
+		"entity_id":            metadata.EntityID,
+		"sso_url":              metadata.ssoURL(),
+		"slo_url":              metadata.sloURL(),
+		"signing_certificates": certs,
+	}
+	return []interface{}{item}
+}
@@ -0,0 +1,369 @@
+package anypoint
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	org "github.com/mulesoft-anypoint/anypoint-client-go/org"
+)
+
+func resourceOrgSecurityPolicy() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceOrgSecurityPolicyCreate,
+		ReadContext:   resourceOrgSecurityPolicyRead,
+		UpdateContext: resourceOrgSecurityPolicyUpdate,
+		DeleteContext: resourceOrgSecurityPolicyDelete,
+		Description: `
+		Manages the security policy of a master organization: multi-factor authentication enforcement, session timeout, password policy and IP allowlist.
+		Deleting this resource restores the session timeout the organization had before it was managed by this resource and removes the password policy and the IP allowlist, the MFA enforcement is left untouched.
+		`,
+		Schema: map[string]*schema.Schema{
+			"last_updated": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "The last time this resource has been updated locally.",
+			},
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The unique id of this security policy, same as the org_id.",
+			},
+			"org_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The master organization id.",
+			},
+			"mfa_required": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				Description:      "Whether MFA is enforced for the organization's users. Enum values: enabled, disabled",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice([]string{"enabled", "disabled"}, false)),
+			},
+			"session_timeout": {
+				Type:             schema.TypeInt,
+				Optional:         true,
+				Computed:         true,
+				Description:      "The session timeout in minutes, between 15 and 180.",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntBetween(15, 180)),
+			},
+			"password_policy": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "The password policy of the organization's users.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"min_length": {
+							Type:             schema.TypeInt,
+							Optional:         true,
+							Default:          8,
+							Description:      "The minimum length of passwords.",
+							ValidateDiagFunc: validation.ToDiagFunc(validation.IntBetween(8, 128)),
+						},
+						"require_uppercase": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     true,
+							Description: "Whether passwords must contain an uppercase letter.",
+						},
+						"require_lowercase": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     true,
+							Description: "Whether passwords must contain a lowercase letter.",
+						},
+						"require_numbers": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     true,
+							Description: "Whether passwords must contain a number.",
+						},
+						"require_special_characters": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Whether passwords must contain a special character.",
+						},
+						"expiration_days": {
+							Type:             schema.TypeInt,
+							Optional:         true,
+							Default:          0,
+							Description:      "The number of days after which passwords expire, 0 means passwords never expire.",
+							ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(0)),
+						},
+						"history_count": {
+							Type:             schema.TypeInt,
+							Optional:         true,
+							Default:          0,
+							Description:      "The number of previous passwords that cannot be reused.",
+							ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(0)),
+						},
+					},
+				},
+			},
+			"ip_allowlist": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "The list of CIDR blocks allowed to access the organization. An empty list allows any address.",
+				Elem: &schema.Schema{
+					Type:             schema.TypeString,
+					ValidateDiagFunc: validation.ToDiagFunc(validation.IsCIDR),
+				},
+			},
+			"initial_session_timeout": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The session timeout of the organization before it was managed by this resource, restored on deletion. 0 when unknown, the session timeout is then left unchanged on deletion.",
+			},
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+	}
+}
+
+func resourceOrgSecurityPolicyCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	pco := m.(ProviderConfOutput)
+	orgid := d.Get("org_id").(string)
+	authctx := getBGAuthCtx(ctx, &pco)
+
+	res, err := getOrg(authctx, &pco, orgid)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to Get Business Group " + orgid,
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	if !res.GetIsMaster() {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to create security policy for Business Group " + orgid,
+			Detail:   "the security policy can only be managed on the master organization.",
+		})
+		return diags
+	}
+
+	d.Set("initial_session_timeout", int(res.GetSessionTimeout()))
+	diags = applyOrgSecurityPolicy(ctx, &pco, d)
+	if diags.HasError() {
+		return diags
+	}
+	d.SetId(orgid)
+
+	return resourceOrgSecurityPolicyRead(ctx, d, m)
+}
+
+func resourceOrgSecurityPolicyRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	pco := m.(ProviderConfOutput)
+	orgid := d.Id()
+	authctx := getBGAuthCtx(ctx, &pco)
+
+	res, err := getOrg(authctx, &pco, orgid)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to Get Business Group " + orgid,
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	settings, err := getOrgSecuritySettings(ctx, &pco, orgid)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to Get security policy of Business Group " + orgid,
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	values := map[string]interface{}{
+		"org_id":          orgid,
+		"mfa_required":    res.GetMfaRequired(),
+		"session_timeout": int(res.GetSessionTimeout()),
+		"password_policy": flattenOrgPasswordPolicy(settings.PasswordPolicy),
+		"ip_allowlist":    flattenOrgIpAllowlist(settings.IpAllowlist),
+	}
+	for attr, val := range values {
+		if err := d.Set(attr, val); err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Unable to set security policy attribute " + attr + " of Business Group " + orgid,
+				Detail:   err.Error(),
+			})
+			return diags
+		}
+	}
+
+	return diags
+}
+
+func resourceOrgSecurityPolicyUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	pco := m.(ProviderConfOutput)
+
+	if d.HasChanges("mfa_required", "session_timeout", "password_policy", "ip_allowlist") {
+		diags := applyOrgSecurityPolicy(ctx, &pco, d)
+		if diags.HasError() {
+			return diags
+		}
+		d.Set("last_updated", time.Now().Format(time.RFC850))
+	}
+
+	return resourceOrgSecurityPolicyRead(ctx, d, m)
+}
+
+func resourceOrgSecurityPolicyDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	pco := m.(ProviderConfOutput)
+	orgid := d.Id()
+
+	body := map[string]interface{}{
+		"passwordPolicy": nil,
+		"ipAllowlist":    []string{},
+	}
+	// the session timeout is only restored when its value prior to this resource is known
+	if initial := d.Get("initial_session_timeout").(int); initial > 0 {
+		body["sessionTimeout"] = initial
+	}
+	if _, err := doAnypointRequest(ctx, &pco, http.MethodPut, "/accounts/api/organizations/"+orgid, body, nil); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to reset security policy of Business Group " + orgid,
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	// d.SetId("") is automatically called assuming delete returns no errors, but
+	// it is added here for explicitness.
+	d.SetId("")
+
+	return diags
+}
+
+/*
+Applies the configured security policy to the organization.
+The MFA enforcement and the session timeout are left untouched when not set,
+the password policy and the IP allowlist are sent whenever they change so that removing them from the configuration clears them.
+*/
+func applyOrgSecurityPolicy(ctx context.Context, pco *ProviderConfOutput, d *schema.ResourceData) diag.Diagnostics {
+	var diags diag.Diagnostics
+	orgid := d.Get("org_id").(string)
+
+	body := make(map[string]interface{})
+	if mfa, ok := d.GetOk("mfa_required"); ok {
+		body["mfaRequired"] = mfa.(string)
+	}
+	if timeout, ok := d.GetOk("session_timeout"); ok {
+		body["sessionTimeout"] = timeout.(int)
+	}
+	if d.HasChange("password_policy") {
+		body["passwordPolicy"] = expandOrgPasswordPolicy(d.Get("password_policy").([]interface{}))
+	}
+	if d.HasChange("ip_allowlist") {
+		body["ipAllowlist"] = ListInterface2ListStrings(d.Get("ip_allowlist").(*schema.Set).List())
+	}
+	if len(body) > 0 {
+		if _, err := doAnypointRequest(ctx, pco, http.MethodPut, "/accounts/api/organizations/"+orgid, body, nil); err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Unable to update security policy of Business Group " + orgid,
+				Detail:   err.Error(),
+			})
+			return diags
+		}
+	}
+
+	return diags
+}
+
+func getOrg(authctx context.Context, pco *ProviderConfOutput, orgid string) (*org.MasterBGDetail, error) {
+	res, httpr, err := pco.orgclient.DefaultApi.OrganizationsOrgIdGet(authctx, orgid).Execute()
+	if err != nil {
+		var details string
+		if httpr != nil {
+			b, _ := ioutil.ReadAll(httpr.Body)
+			details = string(b)
+		} else {
+			details = err.Error()
+		}
+		return nil, fmt.Errorf("unable to get organization %s\n details: %s", orgid, details)
+	}
+	httpr.Body.Close()
+	return &res, nil
+}
+
+// the organization's security settings not exposed by the org client
+type orgSecuritySettings struct {
+	PasswordPolicy map[string]interface{} `json:"passwordPolicy"`
+	IpAllowlist    []string               `json:"ipAllowlist"`
+}
+
+// reads the organization's password policy and IP allowlist
+func getOrgSecuritySettings(ctx context.Context, pco *ProviderConfOutput, orgid string) (*orgSecuritySettings, error) {
+	var settings orgSecuritySettings
+	if _, err := doAnypointRequest(ctx, pco, http.MethodGet, "/accounts/api/organizations/"+orgid, nil, &settings); err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+func expandOrgPasswordPolicy(list []interface{}) map[string]interface{} {
+	if len(list) == 0 || list[0] == nil {
+		return nil
+	}
+	data := list[0].(map[string]interface{})
+	return map[string]interface{}{
+		"minLength":                data["min_length"].(int),
+		"requireUppercase":         data["require_uppercase"].(bool),
+		"requireLowercase":         data["require_lowercase"].(bool),
+		"requireNumbers":           data["require_numbers"].(bool),
+		"requireSpecialCharacters": data["require_special_characters"].(bool),
+		"expirationDays":           data["expiration_days"].(int),
+		"historyCount":             data["history_count"].(int),
+	}
+}
+
+func flattenOrgPasswordPolicy(policy map[string]interface{}) []interface{} {
+	if policy == nil {
+		return []interface{}{}
+	}
+	toInt := func(v interface{}) int {
+		n, _ := v.(float64)
+		return int(n)
+	}
+	toBool := func(v interface{}) bool {
+		b, _ := v.(bool)
+		return b
+	}
+	item := map[string]interface{}{
+		"min_length":                 toInt(policy["minLength"]),
+		"require_uppercase":          toBool(policy["requireUppercase"]),
+		"require_lowercase":          toBool(policy["requireLowercase"]),
+		"require_numbers":            toBool(policy["requireNumbers"]),
+		"require_special_characters": toBool(policy["requireSpecialCharacters"]),
+		"expiration_days":            toInt(policy["expirationDays"]),
+		"history_count":              toInt(policy["historyCount"]),
+	}
+	return []interface{}{item}
+}
+
+func flattenOrgIpAllowlist(allowlist []string) []interface{} {
+	list := make([]interface{}, len(allowlist))
+	for i, ip := range allowlist {
+		list[i] = ip
+	}
+	return list
+}
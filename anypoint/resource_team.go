@@ -2,6 +2,7 @@ package anypoint
 
 import (
 	"context"
+	"fmt"
 	"io/ioutil"
 	"time"
 
@@ -17,6 +18,7 @@ func resourceTeam() *schema.Resource {
 		ReadContext:   resourceTeamRead,
 		UpdateContext: resourceTeamUpdate,
 		DeleteContext: resourceTeamDelete,
+		CustomizeDiff: resourceTeamCustomizeDiff,
 		Description: `
 		Creates a ` + "`" + `team` + "`" + ` for your ` + "`" + `org` + "`" + `.
 		`,
@@ -41,7 +43,7 @@ func resourceTeam() *schema.Resource {
 			"parent_team_id": {
 				Type:        schema.TypeString,
 				Required:    true,
-				Description: "The team_id of the parent of this team.",
+				Description: "The team_id of the parent of this team. Changing the parent moves the team in place, the parent should exist in the same org and cannot be a descendant of this team.",
 			},
 			"team_name": {
 				Type:        schema.TypeString,
@@ -85,6 +87,9 @@ func resourceTeam() *schema.Resource {
 				Description: "The time the team was last modified.",
 			},
 		},
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateCompositeId("org_id"),
+		},
 	}
 }
 
@@ -160,6 +165,10 @@ func resourceTeamRead(ctx context.Context, d *schema.ResourceData, m interface{}
 		})
 		return diags
 	}
+	// the parent is the last ancestor, keeps track of moves performed outside terraform
+	if ancestors := res.GetAncestorTeamIds(); len(ancestors) > 0 {
+		d.Set("parent_team_id", ancestors[len(ancestors)-1])
+	}
 
 	return diags
 }
@@ -254,6 +263,48 @@ func resourceTeamDelete(ctx context.Context, d *schema.ResourceData, m interface
 	return diags
 }
 
+/*
+Validates the parent team at plan time.
+The parent team should exist in the same org and moving the team under it should not create a cycle.
+*/
+func resourceTeamCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.HasChange("parent_team_id") || !d.NewValueKnown("parent_team_id") || !d.NewValueKnown("org_id") {
+		return nil
+	}
+	pco := m.(ProviderConfOutput)
+	teamid := d.Id()
+	orgid := d.Get("org_id").(string)
+	parentid := d.Get("parent_team_id").(string)
+	authctx := getTeamAuthCtx(ctx, &pco)
+
+	if teamid != "" && teamid == parentid {
+		return fmt.Errorf("team %s cannot be its own parent", teamid)
+	}
+
+	res, httpr, err := pco.teamclient.DefaultApi.OrganizationsOrgIdTeamsTeamIdGet(authctx, orgid, parentid).Execute()
+	if err != nil {
+		var details string
+		if httpr != nil {
+			b, _ := ioutil.ReadAll(httpr.Body)
+			details = string(b)
+		} else {
+			details = err.Error()
+		}
+		return fmt.Errorf("unable to find parent team %s in org %s\n details: %s", parentid, orgid, details)
+	}
+	defer httpr.Body.Close()
+
+	if res.GetOrgId() != orgid {
+		return fmt.Errorf("parent team %s belongs to org %s and not to org %s", parentid, res.GetOrgId(), orgid)
+	}
+	// the team cannot be moved under one of its own descendants
+	if teamid != "" && StringInSlice(res.GetAncestorTeamIds(), teamid, false) {
+		return fmt.Errorf("unable to move team %s under %s, the target parent is a descendant of the team", teamid, parentid)
+	}
+
+	return nil
+}
+
 func newTeamPostBody(d *schema.ResourceData) *team.TeamPostBody {
 	body := new(team.TeamPostBody)
 
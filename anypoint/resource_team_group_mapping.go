@@ -0,0 +1,325 @@
+package anypoint
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func resourceTeamGroupMapping() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceTeamGroupMappingCreate,
+		ReadContext:   resourceTeamGroupMappingRead,
+		UpdateContext: resourceTeamGroupMappingUpdate,
+		DeleteContext: resourceTeamGroupMappingDelete,
+		Description: `
+		Maps a single identity provider's group to a team.
+		Unlike ` + "`" + `anypoint_team_group_mappings` + "`" + `, only the given group mapping is managed, the other mappings of the team are left untouched.
+		Do not use this resource along with ` + "`" + `anypoint_team_group_mappings` + "`" + ` on the same team.
+		`,
+		Schema: map[string]*schema.Schema{
+			"last_updated": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "The last time this resource has been updated locally.",
+			},
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The unique id of this group mapping composed by {org_id}/{team_id}/{provider_id}/{external_group_name}",
+			},
+			"org_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The master organization id where the team is defined.",
+			},
+			"team_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The id of the team. team_id is globally unique",
+			},
+			"external_group_name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The group name in the external identity provider that should be mapped to this team.",
+			},
+			"provider_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The id of the identity provider in anypoint platform.",
+			},
+			"membership_type": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "member",
+				Description:      "Whether the mapped member is a regular member or a maintainer. Only users may be team maintainers. Enum values: member, maintainer",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice([]string{"member", "maintainer"}, false)),
+			},
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+	}
+}
+
+func resourceTeamGroupMappingCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	pco := m.(ProviderConfOutput)
+	orgid := d.Get("org_id").(string)
+	teamid := d.Get("team_id").(string)
+	providerid := d.Get("provider_id").(string)
+	groupname := d.Get("external_group_name").(string)
+	membershiptype := d.Get("membership_type").(string)
+
+	diags := upsertTeamGroupMapping(ctx, &pco, orgid, teamid, providerid, groupname, membershiptype, d.Timeout(schema.TimeoutCreate))
+	if diags.HasError() {
+		return diags
+	}
+
+	d.SetId(ComposeResourceId([]string{orgid, teamid, providerid, groupname}))
+	d.Set("last_updated", time.Now().Format(time.RFC850))
+
+	return resourceTeamGroupMappingRead(ctx, d, m)
+}
+
+func resourceTeamGroupMappingRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	pco := m.(ProviderConfOutput)
+	orgid, teamid, providerid, groupname, err := decomposeTeamGroupMappingId(d)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Invalid team group mapping id",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	authctx := getTeamGroupMappingsAuthCtx(ctx, &pco)
+
+	list, err := getAllTeamGroupMappings(authctx, &pco, orgid, teamid)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to get team " + teamid + " groupmappings",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	i := indexOfTeamGroupMapping(list, providerid, groupname)
+	if i < 0 {
+		// the mapping has been removed outside terraform
+		d.SetId("")
+		return diags
+	}
+	mapping := list[i].(map[string]interface{})
+
+	if err := d.Set("membership_type", mapping["membership_type"]); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to set group mapping " + d.Id(),
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	// setting resource id components for import purposes
+	d.Set("org_id", orgid)
+	d.Set("team_id", teamid)
+	d.Set("provider_id", providerid)
+	d.Set("external_group_name", groupname)
+
+	return diags
+}
+
+func resourceTeamGroupMappingUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	pco := m.(ProviderConfOutput)
+	orgid, teamid, providerid, groupname, err := decomposeTeamGroupMappingId(d)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Invalid team group mapping id",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	if d.HasChange("membership_type") {
+		membershiptype := d.Get("membership_type").(string)
+		diags = upsertTeamGroupMapping(ctx, &pco, orgid, teamid, providerid, groupname, membershiptype, d.Timeout(schema.TimeoutUpdate))
+		if diags.HasError() {
+			return diags
+		}
+		d.Set("last_updated", time.Now().Format(time.RFC850))
+	}
+
+	return resourceTeamGroupMappingRead(ctx, d, m)
+}
+
+func resourceTeamGroupMappingDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	pco := m.(ProviderConfOutput)
+	orgid, teamid, providerid, groupname, err := decomposeTeamGroupMappingId(d)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Invalid team group mapping id",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	modify := func(list []interface{}) []interface{} {
+		return FilterMapList(list, func(item map[string]interface{}) bool {
+			return !isTeamGroupMapping(item, providerid, groupname)
+		})
+	}
+	check := func(list []interface{}) bool {
+		return indexOfTeamGroupMapping(list, providerid, groupname) < 0
+	}
+	diags = modifyTeamGroupMappings(ctx, &pco, orgid, teamid, d.Timeout(schema.TimeoutDelete), modify, check)
+	if diags.HasError() {
+		return diags
+	}
+	// d.SetId("") is automatically called assuming delete returns no errors, but
+	// it is added here for explicitness.
+	d.SetId("")
+
+	return diags
+}
+
+// adds the given group mapping to the team or updates its membership type if it already exists
+func upsertTeamGroupMapping(ctx context.Context, pco *ProviderConfOutput, orgid, teamid, providerid, groupname, membershiptype string, timeout time.Duration) diag.Diagnostics {
+	modify := func(list []interface{}) []interface{} {
+		mapping := map[string]interface{}{
+			"external_group_name": groupname,
+			"provider_id":         providerid,
+			"membership_type":     membershiptype,
+		}
+		if i := indexOfTeamGroupMapping(list, providerid, groupname); i >= 0 {
+			list[i] = mapping
+			return list
+		}
+		return append(list, mapping)
+	}
+	check := func(list []interface{}) bool {
+		i := indexOfTeamGroupMapping(list, providerid, groupname)
+		return i >= 0 && list[i].(map[string]interface{})["membership_type"] == membershiptype
+	}
+	return modifyTeamGroupMappings(ctx, pco, orgid, teamid, timeout, modify, check)
+}
+
+/*
+Performs a read-modify-write of the team's group mappings.
+The written mappings are read back and verified using the check function,
+the whole cycle is retried if a concurrent modification is detected.
+*/
+func modifyTeamGroupMappings(ctx context.Context, pco *ProviderConfOutput, orgid, teamid string, timeout time.Duration, modify func([]interface{}) []interface{}, check func([]interface{}) bool) diag.Diagnostics {
+	var diags diag.Diagnostics
+	authctx := getTeamGroupMappingsAuthCtx(ctx, pco)
+	key := "team " + teamid + " groupmappings"
+
+	write := func() *resource.RetryError {
+		current, err := getAllTeamGroupMappings(authctx, pco, orgid, teamid)
+		if err != nil {
+			return resource.NonRetryableError(err)
+		}
+		body := teamGroupMappingsList2PutBody(modify(current))
+		httpr, err := pco.teamgroupmappingsclient.DefaultApi.OrganizationsOrgIdTeamsTeamIdGroupmappingsPut(authctx, orgid, teamid).RequestBody(body).Execute()
+		if err != nil {
+			return readModifyWriteError(key, httpr, err)
+		}
+		httpr.Body.Close()
+		return nil
+	}
+	verify := func() (bool, error) {
+		written, err := getAllTeamGroupMappings(authctx, pco, orgid, teamid)
+		if err != nil {
+			return false, err
+		}
+		return check(written), nil
+	}
+	if err := readModifyWrite(ctx, key, timeout, write, verify); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to update team " + teamid + " groupmappings",
+			Detail:   err.Error(),
+		})
+	}
+
+	return diags
+}
+
+// loads all group mappings of the given team going through all pages
+func getAllTeamGroupMappings(authctx context.Context, pco *ProviderConfOutput, orgid, teamid string) ([]interface{}, error) {
+	const limit = 500
+	result := make([]interface{}, 0)
+	for offset := 0; ; offset += limit {
+		res, httpr, err := pco.teamgroupmappingsclient.DefaultApi.OrganizationsOrgIdTeamsTeamIdGroupmappingsGet(authctx, orgid, teamid).Offset(int32(offset)).Limit(limit).Execute()
+		if err != nil {
+			var details string
+			if httpr != nil {
+				b, _ := ioutil.ReadAll(httpr.Body)
+				details = string(b)
+			} else {
+				details = err.Error()
+			}
+			return nil, fmt.Errorf("unable to get team %s groupmappings\n details: %s", teamid, details)
+		}
+		httpr.Body.Close()
+		data := res.GetData()
+		result = append(result, flattenTeamGroupMappingsData(&data)...)
+		if len(data) < limit || len(result) >= int(res.GetTotal()) {
+			return result, nil
+		}
+	}
+}
+
+func teamGroupMappingsList2PutBody(list []interface{}) []map[string]interface{} {
+	body := make([]map[string]interface{}, len(list))
+	for i, item := range list {
+		body[i] = item.(map[string]interface{})
+	}
+	return body
+}
+
+// returns the index of the group mapping identified by the given provider and group name, -1 if not found
+func indexOfTeamGroupMapping(list []interface{}, providerid, groupname string) int {
+	for i, item := range list {
+		if isTeamGroupMapping(item.(map[string]interface{}), providerid, groupname) {
+			return i
+		}
+	}
+	return -1
+}
+
+func isTeamGroupMapping(item map[string]interface{}, providerid, groupname string) bool {
+	p, _ := item["provider_id"].(string)
+	g, _ := item["external_group_name"].(string)
+	return p == providerid && g == groupname
+}
+
+func decomposeTeamGroupMappingId(d *schema.ResourceData) (string, string, string, string, error) {
+	s := DecomposeResourceId(d.Id())
+	if len(s) < 4 {
+		return "", "", "", "", fmt.Errorf("invalid team group mapping id %q, expected {org_id}%[2]s{team_id}%[2]s{provider_id}%[2]s{external_group_name}", d.Id(), COMPOSITE_ID_SEPARATOR)
+	}
+	// the external group name may contain the separator
+	return s[0], s[1], s[2], strings.Join(s[3:], COMPOSITE_ID_SEPARATOR), nil
+}
@@ -84,6 +84,9 @@ Depending on the ` + "`" + `role` + "`" + `, some roles are environment scoped o
 				Computed:    true,
 			},
 		},
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
 	}
 }
 
@@ -152,6 +155,9 @@ func resourceTeamRolesRead(ctx context.Context, d *schema.ResourceData, m interf
 
 	//process data
 	roles := flattenTeamRolesData(res.Data)
+	// setting resource id components for import purposes
+	d.Set("org_id", orgid)
+	d.Set("team_id", teamid)
 	//save in data source schema
 	if err := d.Set("roles", roles); err != nil {
 		diags = append(diags, diag.Diagnostic{
@@ -2,7 +2,10 @@ package anypoint
 
 import (
 	"context"
+	"fmt"
 	"io/ioutil"
+	"net/http"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
@@ -16,8 +19,12 @@ func resourceUser() *schema.Resource {
 		ReadContext:   resourceUserRead,
 		UpdateContext: resourceUserUpdate,
 		DeleteContext: resourceUserDelete,
+		CustomizeDiff: resourceUserCustomizeDiff,
 		Description: `
 		Creates a ` + "`" + `user` + "`" + ` for your org. 
+		The user is either created with a password or invited by email using the invite mode.
+		The password is only sent when the user is created and is not kept in the state.
+		A pending invitation can be resent using the invitation_resend_trigger and is revoked when the resource is destroyed.
 
 **N.B:** you can use a username only once even after it's deleted.
 		`,
@@ -39,22 +46,47 @@ func resourceUser() *schema.Resource {
 				ForceNew:    true,
 				Description: "The master organization id where the user is defined.",
 			},
+			"invite": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				ForceNew:    true,
+				Description: "Whether to send an email invitation instead of creating the user with a password. The invited user chooses its username, name and password when accepting the invitation.",
+			},
+			"invitation_resend_trigger": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Any change of this value resends the invitation if it is still pending. Only applies in invite mode.",
+			},
+			"invitation_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The id of the invitation sent to the user in invite mode.",
+			},
+			"invitation_status": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The status of the invitation in invite mode: pending, expired or accepted.",
+			},
 			"username": {
 				Type:        schema.TypeString,
-				Required:    true,
-				Description: "The username of this user.",
+				Optional:    true,
+				Computed:    true,
+				Description: "The username of this user. Required unless the user is invited.",
 			},
 			"first_name": {
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
+				Computed:    true,
 				Sensitive:   true,
-				Description: "The firstname of this user.",
+				Description: "The firstname of this user. Required unless the user is invited.",
 			},
 			"last_name": {
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
+				Computed:    true,
 				Sensitive:   true,
-				Description: "The lastname of this user.",
+				Description: "The lastname of this user. Required unless the user is invited.",
 			},
 			"email": {
 				Type:        schema.TypeString,
@@ -64,15 +96,25 @@ func resourceUser() *schema.Resource {
 			},
 			"phone_number": {
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
+				Computed:    true,
 				Sensitive:   true,
-				Description: "The phone number of this user.",
+				Description: "The phone number of this user. Required unless the user is invited.",
 			},
 			"password": {
-				Type:        schema.TypeString,
-				Required:    true,
-				Sensitive:   true,
-				Description: "The password of this user.",
+				Type:      schema.TypeString,
+				Optional:  true,
+				Sensitive: true,
+				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+					// the password is create-only and never kept in the state
+					return d.Id() != ""
+				},
+				Description: "The password of this user, only sent when the user is created. It is never kept in the state and changing it afterwards has no effect, the user manages its password from the platform. Required unless the user is invited.",
+			},
+			"email_verified": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether this user has verified its email.",
 			},
 			"organization_id": {
 				Type:        schema.TypeString,
@@ -170,6 +212,10 @@ func resourceUserCreate(ctx context.Context, d *schema.ResourceData, m interface
 	pco := m.(ProviderConfOutput)
 	orgid := d.Get("org_id").(string)
 
+	if d.Get("invite").(bool) {
+		return resourceUserInviteCreate(ctx, d, m)
+	}
+
 	authctx := getUserAuthCtx(ctx, &pco)
 
 	body := newUserPostBody(d)
@@ -207,6 +253,10 @@ func resourceUserRead(ctx context.Context, d *schema.ResourceData, m interface{}
 	userid := d.Id()
 	orgid := d.Get("org_id").(string)
 
+	if isUserInvitationPending(d) {
+		return resourceUserInviteRead(ctx, d, m)
+	}
+
 	authctx := getUserAuthCtx(ctx, &pco)
 
 	res, httpr, err := pco.userclient.DefaultApi.OrganizationsOrgIdUsersUserIdGet(authctx, orgid, userid).Execute()
@@ -226,6 +276,8 @@ func resourceUserRead(ctx context.Context, d *schema.ResourceData, m interface{}
 		return diags
 	}
 
+	defer httpr.Body.Close()
+
 	//process data
 	user := flattenUserData(&res)
 	//save in data source schema
@@ -238,6 +290,21 @@ func resourceUserRead(ctx context.Context, d *schema.ResourceData, m interface{}
 		return diags
 	}
 
+	//the password is create-only, scrub any value left in the state
+	d.Set("password", "")
+
+	//the email verification is informative, it does not fail the refresh
+	verified, err := getUserEmailVerified(ctx, &pco, orgid, userid)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "Unable to get email verification of User " + userid,
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	d.Set("email_verified", verified)
+
 	return diags
 }
 
@@ -247,6 +314,10 @@ func resourceUserUpdate(ctx context.Context, d *schema.ResourceData, m interface
 	userid := d.Id()
 	orgid := d.Get("org_id").(string)
 
+	if isUserInvitationPending(d) {
+		return resourceUserInviteUpdate(ctx, d, m)
+	}
+
 	authctx := getUserAuthCtx(ctx, &pco)
 
 	if d.HasChanges(getUserWatchAttributes()...) {
@@ -283,6 +354,10 @@ func resourceUserDelete(ctx context.Context, d *schema.ResourceData, m interface
 	userid := d.Id()
 	orgid := d.Get("org_id").(string)
 
+	if isUserInvitationPending(d) {
+		return resourceUserInviteDelete(ctx, d, m)
+	}
+
 	authctx := getUserAuthCtx(ctx, &pco)
 
 	httpr, err := pco.userclient.DefaultApi.OrganizationsOrgIdUsersUserIdDelete(authctx, orgid, userid).Execute()
@@ -309,6 +384,249 @@ func resourceUserDelete(ctx context.Context, d *schema.ResourceData, m interface
 	return diags
 }
 
+/*
+Validates the attributes required by the creation mode of the user
+*/
+func resourceUserCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.Id() != "" {
+		return nil
+	}
+	invite := d.Get("invite").(bool)
+	config := d.GetRawConfig()
+	if invite && !config.GetAttr("password").IsNull() {
+		return fmt.Errorf("password cannot be set when the user is invited")
+	}
+	if !invite {
+		for _, attr := range []string{"username", "first_name", "last_name", "phone_number", "password"} {
+			if config.GetAttr(attr).IsNull() {
+				return fmt.Errorf("%s is required unless the user is invited", attr)
+			}
+		}
+	}
+	return nil
+}
+
+func resourceUserInviteCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	pco := m.(ProviderConfOutput)
+	orgid := d.Get("org_id").(string)
+	email := d.Get("email").(string)
+
+	invitation, err := sendUserInvitation(ctx, &pco, orgid, email)
+	if err != nil {
+		diags := append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to Invite User " + email,
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	d.SetId(invitation.Id)
+	d.Set("invitation_id", invitation.Id)
+	d.Set("invitation_status", "pending")
+
+	return resourceUserInviteRead(ctx, d, m)
+}
+
+/*
+Reads the invitation of a user who hasn't accepted it yet.
+Once the invitation is accepted, the resource tracks the user created by the platform.
+*/
+func resourceUserInviteRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	pco := m.(ProviderConfOutput)
+	orgid := d.Get("org_id").(string)
+	invitationid := d.Get("invitation_id").(string)
+	email := d.Get("email").(string)
+
+	invitations, err := getUserInvitations(ctx, &pco, orgid)
+	if err != nil {
+		diags := append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to Get User Invitation " + invitationid,
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	for _, invitation := range invitations {
+		if invitation.Id != invitationid {
+			continue
+		}
+		status := "pending"
+		if expiresat, err := time.Parse(time.RFC3339, invitation.ExpiresAt); err == nil && expiresat.Before(time.Now()) {
+			status = "expired"
+		}
+		d.Set("invitation_status", status)
+		return diags
+	}
+
+	// the invitation doesn't exist anymore, it has either been accepted or revoked outside terraform
+	userid, err := findUserIdByEmail(ctx, &pco, orgid, email)
+	if err != nil {
+		diags := append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to Get User " + email,
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	if userid == "" {
+		d.SetId("")
+		return diags
+	}
+	d.SetId(userid)
+	d.Set("invitation_status", "accepted")
+
+	return resourceUserRead(ctx, d, m)
+}
+
+func resourceUserInviteUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	pco := m.(ProviderConfOutput)
+	orgid := d.Get("org_id").(string)
+
+	// a pending invitation is resent by revoking it and sending a new one
+	if d.HasChanges("email", "invitation_resend_trigger") {
+		if err := revokeUserInvitation(ctx, &pco, orgid, d.Get("invitation_id").(string)); err != nil {
+			diags := append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Unable to Revoke User Invitation " + d.Get("invitation_id").(string),
+				Detail:   err.Error(),
+			})
+			return diags
+		}
+		d.Set("last_updated", time.Now().Format(time.RFC850))
+		return resourceUserInviteCreate(ctx, d, m)
+	}
+
+	return resourceUserInviteRead(ctx, d, m)
+}
+
+func resourceUserInviteDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	pco := m.(ProviderConfOutput)
+	orgid := d.Get("org_id").(string)
+	invitationid := d.Get("invitation_id").(string)
+
+	if err := revokeUserInvitation(ctx, &pco, orgid, invitationid); err != nil {
+		diags := append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to Revoke User Invitation " + invitationid,
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	// d.SetId("") is automatically called assuming delete returns no errors, but
+	// it is added here for explicitness.
+	d.SetId("")
+
+	return diags
+}
+
+// returns true if the user has been invited and has not accepted the invitation yet
+func isUserInvitationPending(d *schema.ResourceData) bool {
+	return d.Get("invite").(bool) && d.Get("invitation_status").(string) != "accepted"
+}
+
+// the invitation sent to a user in invite mode
+type userInvitation struct {
+	Id        string `json:"id"`
+	Email     string `json:"email"`
+	CreatedAt string `json:"createdAt"`
+	ExpiresAt string `json:"expiresAt"`
+}
+
+// sends an email invitation to join the organization
+func sendUserInvitation(ctx context.Context, pco *ProviderConfOutput, orgid string, email string) (*userInvitation, error) {
+	body := map[string]interface{}{
+		"emails": []string{email},
+	}
+	var res []userInvitation
+	if _, err := doAnypointRequest(ctx, pco, http.MethodPost, "/accounts/api/organizations/"+orgid+"/invites", body, &res); err != nil {
+		return nil, err
+	}
+	for _, invitation := range res {
+		if strings.EqualFold(invitation.Email, email) {
+			return &invitation, nil
+		}
+	}
+	return nil, fmt.Errorf("no invitation has been returned for %s", email)
+}
+
+// loads all the pending invitations of the organization
+func getUserInvitations(ctx context.Context, pco *ProviderConfOutput, orgid string) ([]userInvitation, error) {
+	const limit = 200
+	result := make([]userInvitation, 0)
+	for offset := 0; ; offset += limit {
+		var res struct {
+			Data  []userInvitation `json:"data"`
+			Total int              `json:"total"`
+		}
+		path := fmt.Sprintf("/accounts/api/organizations/%s/invites?offset=%d&limit=%d", orgid, offset, limit)
+		if _, err := doAnypointRequest(ctx, pco, http.MethodGet, path, nil, &res); err != nil {
+			return nil, err
+		}
+		result = append(result, res.Data...)
+		if len(res.Data) < limit || len(result) >= res.Total {
+			return result, nil
+		}
+	}
+}
+
+// revokes the given invitation, an invitation that doesn't exist anymore is considered revoked
+func revokeUserInvitation(ctx context.Context, pco *ProviderConfOutput, orgid string, invitationid string) error {
+	httpr, err := doAnypointRequest(ctx, pco, http.MethodDelete, "/accounts/api/organizations/"+orgid+"/invites/"+invitationid, nil, nil)
+	if err != nil && (httpr == nil || httpr.StatusCode != http.StatusNotFound) {
+		return err
+	}
+	return nil
+}
+
+// looks up the id of the user having the given email, returns an empty string if not found
+func findUserIdByEmail(ctx context.Context, pco *ProviderConfOutput, orgid string, email string) (string, error) {
+	const limit = 500
+	authctx := getUserAuthCtx(ctx, pco)
+	for offset := 0; ; offset += limit {
+		res, httpr, err := pco.userclient.DefaultApi.OrganizationsOrgIdUsersGet(authctx, orgid).Offset(int32(offset)).Limit(limit).Execute()
+		if err != nil {
+			var details string
+			if httpr != nil {
+				b, _ := ioutil.ReadAll(httpr.Body)
+				details = string(b)
+			} else {
+				details = err.Error()
+			}
+			return "", fmt.Errorf("%s", details)
+		}
+		httpr.Body.Close()
+		data := res.GetData()
+		for _, usr := range data {
+			if strings.EqualFold(usr.GetEmail(), email) {
+				return usr.GetId(), nil
+			}
+		}
+		if len(data) < limit || offset+len(data) >= int(res.GetTotal()) {
+			return "", nil
+		}
+	}
+}
+
+// returns whether the user has verified its email
+func getUserEmailVerified(ctx context.Context, pco *ProviderConfOutput, orgid string, userid string) (bool, error) {
+	var res struct {
+		EmailVerified   *bool   `json:"emailVerified"`
+		EmailVerifiedAt *string `json:"emailVerifiedAt"`
+	}
+	if _, err := doAnypointRequest(ctx, pco, http.MethodGet, "/accounts/api/organizations/"+orgid+"/users/"+userid, nil, &res); err != nil {
+		return false, err
+	}
+	if res.EmailVerified != nil {
+		return *res.EmailVerified, nil
+	}
+	return res.EmailVerifiedAt != nil && *res.EmailVerifiedAt != "", nil
+}
+
 func newUserPostBody(d *schema.ResourceData) *user.UserPostBody {
 	body := new(user.UserPostBody)
 
@@ -327,8 +645,8 @@ func newUserPostBody(d *schema.ResourceData) *user.UserPostBody {
 	if phone_number := d.Get("phone_number"); phone_number != nil {
 		body.SetPhoneNumber(d.Get("phone_number").(string))
 	}
-	if password := d.Get("password"); password != nil {
-		body.SetPassword(password.(string))
+	if password := d.Get("password").(string); password != "" {
+		body.SetPassword(password)
 	}
 
 	return body
@@ -352,9 +670,6 @@ func newUserPutBody(d *schema.ResourceData) *user.UserPutBody {
 	if phone_number := d.Get("phone_number"); phone_number != nil {
 		body.SetPhoneNumber(d.Get("phone_number").(string))
 	}
-	if password := d.Get("password"); password != nil {
-		body.SetPassword(password.(string))
-	}
 
 	return body
 }
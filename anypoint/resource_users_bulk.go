@@ -0,0 +1,874 @@
+package anypoint
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	team_members "github.com/mulesoft-anypoint/anypoint-client-go/team_members"
+	"github.com/mulesoft-anypoint/anypoint-client-go/user"
+)
+
+// a user entry of the bulk provisioning, teams are indexed by team id and hold the membership type
+type usersBulkEntry struct {
+	Username    string            `json:"username"`
+	FirstName   string            `json:"first_name"`
+	LastName    string            `json:"last_name"`
+	Email       string            `json:"email"`
+	PhoneNumber string            `json:"phone_number"`
+	Password    string            `json:"password"`
+	Teams       map[string]string `json:"-"`
+}
+
+// the outcome of the reconciliation of a single user
+type usersBulkResult struct {
+	username string
+	id       string
+	err      error
+}
+
+func resourceUsersBulk() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceUsersBulkCreate,
+		ReadContext:   resourceUsersBulkRead,
+		UpdateContext: resourceUsersBulkUpdate,
+		DeleteContext: resourceUsersBulkDelete,
+		CustomizeDiff: resourceUsersBulkCustomizeDiff,
+		Description: `
+		Creates and reconciles a list of ` + "`" + `users` + "`" + ` for your org, along with their team memberships.
+		Users can be provided as a list of blocks, a CSV document or a JSON document.
+		Failures are reported per user as warnings, failed users are retried on the next apply.
+
+**N.B:** you can use a username only once even after it's deleted.
+		`,
+		Schema: map[string]*schema.Schema{
+			"last_updated": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "The last time this resource has been updated locally.",
+			},
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The unique id of this users bulk generated by the provider composed of {org_id}/users_bulk.",
+			},
+			"org_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The master organization id where the users are defined.",
+			},
+			"batch_size": {
+				Type:             schema.TypeInt,
+				Optional:         true,
+				Default:          10,
+				Description:      "The number of users reconciled concurrently.",
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntBetween(1, 50)),
+			},
+			"users": {
+				Type:         schema.TypeList,
+				Optional:     true,
+				ExactlyOneOf: []string{"users", "users_csv", "users_json"},
+				Description:  "The list of users to provision.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"username": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The username of this user.",
+						},
+						"first_name": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Sensitive:   true,
+							Description: "The firstname of this user.",
+						},
+						"last_name": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Sensitive:   true,
+							Description: "The lastname of this user.",
+						},
+						"email": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Sensitive:   true,
+							Description: "The email of this user.",
+						},
+						"phone_number": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Sensitive:   true,
+							Description: "The phone number of this user.",
+						},
+						"password": {
+							Type:      schema.TypeString,
+							Optional:  true,
+							Sensitive: true,
+							DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+								// the password is create-only and never kept in the state
+								return isUsersBulkUserProvisioned(d, strings.TrimSuffix(k, "password")+"username")
+							},
+							Description: "The password of this user, only sent when the user is created. It is never kept in the state and changing it afterwards has no effect.",
+						},
+						"teams": {
+							Type:        schema.TypeSet,
+							Optional:    true,
+							Description: "The teams this user should be member of.",
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"team_id": {
+										Type:        schema.TypeString,
+										Required:    true,
+										Description: "The id of the team.",
+									},
+									"membership_type": {
+										Type:             schema.TypeString,
+										Optional:         true,
+										Default:          "member",
+										Description:      "Whether the user is a regular member or a maintainer. Enum values: member, maintainer",
+										ValidateDiagFunc: validation.ToDiagFunc(validation.StringInSlice([]string{"member", "maintainer"}, true)),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"users_csv": {
+				Type:      schema.TypeString,
+				Optional:  true,
+				Sensitive: true,
+				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+					// the passwords are removed from the document kept in the state
+					return redactUsersBulkCSV(old) == redactUsersBulkCSV(new)
+				},
+				Description: `
+				A CSV document describing the users to provision. The first line is the header and should contain the username, first_name, last_name and email columns.
+				The phone_number, password and teams columns are optional.
+				Teams are separated by semicolons, a team can be followed by its membership type, for instance: team_id_1:maintainer;team_id_2
+				The passwords are only sent when the users are created, they are removed from the document kept in the state.
+				`,
+			},
+			"users_json": {
+				Type:      schema.TypeString,
+				Optional:  true,
+				Sensitive: true,
+				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+					// the passwords are removed from the document kept in the state
+					return redactUsersBulkJSON(old) == redactUsersBulkJSON(new)
+				},
+				Description: `
+				A JSON array describing the users to provision. Each object has the username, first_name, last_name, email, phone_number and password attributes,
+				and an optional teams array of objects with team_id and membership_type attributes.
+				The passwords are only sent when the users are created, they are removed from the document kept in the state.
+				`,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.StringIsJSON),
+			},
+			"user_ids": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "The ids of the provisioned users indexed by username.",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"unreconciled_users": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The usernames of the users that failed to reconcile or that changed outside terraform. They are reconciled on the next apply.",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+	}
+}
+
+func resourceUsersBulkCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	orgid := d.Get("org_id").(string)
+
+	diags := reconcileUsersBulk(ctx, d, m)
+	if diags.HasError() {
+		return diags
+	}
+
+	d.SetId(ComposeResourceId([]string{orgid, "users_bulk"}))
+	d.Set("last_updated", time.Now().Format(time.RFC850))
+
+	return append(diags, resourceUsersBulkRead(ctx, d, m)...)
+}
+
+func resourceUsersBulkRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	pco := m.(ProviderConfOutput)
+	orgid := d.Get("org_id").(string)
+	authctx := getUserAuthCtx(ctx, &pco)
+
+	entries, err := parseUsersBulkEntries(d.Get("users").([]interface{}), d.Get("users_csv").(string), d.Get("users_json").(string))
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to parse users",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	userids := make(map[string]string)
+	for k, v := range d.Get("user_ids").(map[string]interface{}) {
+		userids[k] = v.(string)
+	}
+	unreconciled := make(map[string]bool)
+	for _, v := range d.Get("unreconciled_users").([]interface{}) {
+		unreconciled[v.(string)] = true
+	}
+
+	for username, userid := range userids {
+		res, httpr, err := pco.userclient.DefaultApi.OrganizationsOrgIdUsersUserIdGet(authctx, orgid, userid).Execute()
+		if err != nil {
+			if httpr != nil && httpr.StatusCode == http.StatusNotFound {
+				// the user has been removed outside terraform
+				delete(userids, username)
+				if _, ok := entries[username]; ok {
+					unreconciled[username] = true
+				}
+				continue
+			}
+			var details string
+			if httpr != nil {
+				b, _ := ioutil.ReadAll(httpr.Body)
+				details = string(b)
+			} else {
+				details = err.Error()
+			}
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Unable to Get User " + userid,
+				Detail:   details,
+			})
+			return diags
+		}
+		httpr.Body.Close()
+		if entry, ok := entries[username]; ok && !equalUsersBulkUser(entry, &res) {
+			unreconciled[username] = true
+		}
+	}
+	// desired users that were never created
+	for username := range entries {
+		if _, ok := userids[username]; !ok {
+			unreconciled[username] = true
+		}
+	}
+
+	if err := d.Set("user_ids", userids); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to set user ids",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	if err := d.Set("unreconciled_users", sortedMapKeys(unreconciled)); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to set unreconciled users",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	//the passwords are create-only, scrub any value left in the state
+	if err := scrubUsersBulkPasswords(d); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to remove the users passwords from the state",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+
+	return diags
+}
+
+func resourceUsersBulkUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	diags := reconcileUsersBulk(ctx, d, m)
+	if diags.HasError() {
+		return diags
+	}
+	d.Set("last_updated", time.Now().Format(time.RFC850))
+
+	return append(diags, resourceUsersBulkRead(ctx, d, m)...)
+}
+
+func resourceUsersBulkDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	pco := m.(ProviderConfOutput)
+	orgid := d.Get("org_id").(string)
+	batchsize := d.Get("batch_size").(int)
+
+	tasks := make([]func() usersBulkResult, 0)
+	for username, userid := range d.Get("user_ids").(map[string]interface{}) {
+		username, userid := username, userid.(string)
+		tasks = append(tasks, func() usersBulkResult {
+			return usersBulkResult{username: username, err: deleteUsersBulkUser(ctx, &pco, orgid, userid)}
+		})
+	}
+
+	remaining := make(map[string]interface{})
+	for _, r := range runUsersBulkTasks(tasks, batchsize) {
+		if r.err != nil {
+			remaining[r.username] = d.Get("user_ids").(map[string]interface{})[r.username]
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Unable to Delete User " + r.username,
+				Detail:   r.err.Error(),
+			})
+		}
+	}
+	if diags.HasError() {
+		// keeps track of the users that could not be deleted
+		d.Set("user_ids", remaining)
+		return diags
+	}
+	// d.SetId("") is automatically called assuming delete returns no errors, but
+	// it is added here for explicitness.
+	d.SetId("")
+
+	return diags
+}
+
+/*
+Plans the reconciliation of the users when the desired users change or when some users are out of sync
+*/
+func resourceUsersBulkCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.Id() == "" {
+		return nil
+	}
+	if d.HasChanges("users", "users_csv", "users_json") || len(d.Get("unreconciled_users").([]interface{})) > 0 {
+		if err := d.SetNewComputed("user_ids"); err != nil {
+			return err
+		}
+		return d.SetNewComputed("unreconciled_users")
+	}
+	return nil
+}
+
+/*
+Reconciles the desired users with the users previously provisioned by this resource.
+Users are created, updated or deleted in batches, each failure is reported as a warning and doesn't stop the other users.
+*/
+func reconcileUsersBulk(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	pco := m.(ProviderConfOutput)
+	orgid := d.Get("org_id").(string)
+	batchsize := d.Get("batch_size").(int)
+
+	oldusers, newusers := d.GetChange("users")
+	oldcsv, newcsv := d.GetChange("users_csv")
+	oldjson, newjson := d.GetChange("users_json")
+	entries, err := parseUsersBulkEntries(newusers.([]interface{}), newcsv.(string), newjson.(string))
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Unable to parse users",
+			Detail:   err.Error(),
+		})
+		return diags
+	}
+	// the previous entries are only used to compute the changes, the previous document is valid if it has been applied
+	oldentries, _ := parseUsersBulkEntries(oldusers.([]interface{}), oldcsv.(string), oldjson.(string))
+	if oldentries == nil {
+		oldentries = make(map[string]usersBulkEntry)
+	}
+
+	userids := make(map[string]string)
+	for k, v := range d.Get("user_ids").(map[string]interface{}) {
+		userids[k] = v.(string)
+	}
+	unreconciled := make(map[string]bool)
+	for _, v := range d.Get("unreconciled_users").([]interface{}) {
+		unreconciled[v.(string)] = true
+	}
+
+	tasks := make([]func() usersBulkResult, 0)
+	for username, entry := range entries {
+		entry := entry
+		userid, exists := userids[username]
+		if !exists {
+			tasks = append(tasks, func() usersBulkResult {
+				id, err := createUsersBulkUser(ctx, &pco, orgid, entry)
+				return usersBulkResult{username: entry.Username, id: id, err: err}
+			})
+			continue
+		}
+		old, known := oldentries[username]
+		if unreconciled[username] || !known {
+			// re-applies all the attributes and memberships of the user, only keeps the memberships to remove
+			stale := make(map[string]string)
+			for teamid, membershiptype := range old.Teams {
+				if _, ok := entry.Teams[teamid]; !ok {
+					stale[teamid] = membershiptype
+				}
+			}
+			old = usersBulkEntry{Teams: stale}
+		} else if old.equals(entry) {
+			continue
+		}
+		tasks = append(tasks, func() usersBulkResult {
+			err := updateUsersBulkUser(ctx, &pco, orgid, userid, old, entry)
+			return usersBulkResult{username: entry.Username, id: userid, err: err}
+		})
+	}
+	for username, userid := range userids {
+		if _, ok := entries[username]; ok {
+			continue
+		}
+		username, userid := username, userid
+		tasks = append(tasks, func() usersBulkResult {
+			return usersBulkResult{username: username, err: deleteUsersBulkUser(ctx, &pco, orgid, userid)}
+		})
+	}
+
+	failed := make(map[string]bool)
+	for _, r := range runUsersBulkTasks(tasks, batchsize) {
+		_, desired := entries[r.username]
+		if r.err != nil {
+			failed[r.username] = true
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  "Unable to reconcile user " + r.username,
+				Detail:   r.err.Error(),
+			})
+			// a user created without its memberships is kept track of
+			if desired && r.id != "" {
+				userids[r.username] = r.id
+			}
+			continue
+		}
+		if desired {
+			userids[r.username] = r.id
+		} else {
+			delete(userids, r.username)
+		}
+	}
+
+	d.Set("user_ids", userids)
+	d.Set("unreconciled_users", sortedMapKeys(failed))
+
+	return diags
+}
+
+// creates the user and adds it to its teams. returns the id of the user if it has been created
+func createUsersBulkUser(ctx context.Context, pco *ProviderConfOutput, orgid string, entry usersBulkEntry) (string, error) {
+	authctx := getUserAuthCtx(ctx, pco)
+	body := newUsersBulkUserPostBody(entry)
+	res, httpr, err := pco.userclient.DefaultApi.OrganizationsOrgIdUsersPost(authctx, orgid).UserPostBody(*body).Execute()
+	if err != nil {
+		return "", usersBulkHttpError(httpr, err)
+	}
+	httpr.Body.Close()
+
+	userid := res.GetId()
+	return userid, updateUsersBulkTeams(ctx, pco, orgid, userid, map[string]string{}, entry.Teams)
+}
+
+// updates the user's attributes and team memberships
+func updateUsersBulkUser(ctx context.Context, pco *ProviderConfOutput, orgid string, userid string, old usersBulkEntry, entry usersBulkEntry) error {
+	authctx := getUserAuthCtx(ctx, pco)
+	if !old.equalsAttributes(entry) {
+		body := newUsersBulkUserPutBody(entry)
+		_, httpr, err := pco.userclient.DefaultApi.OrganizationsOrgIdUsersUserIdPut(authctx, orgid, userid).UserPutBody(*body).Execute()
+		if err != nil {
+			return usersBulkHttpError(httpr, err)
+		}
+		httpr.Body.Close()
+	}
+	return updateUsersBulkTeams(ctx, pco, orgid, userid, old.Teams, entry.Teams)
+}
+
+// deletes the user, a user that doesn't exist anymore is considered deleted
+func deleteUsersBulkUser(ctx context.Context, pco *ProviderConfOutput, orgid string, userid string) error {
+	authctx := getUserAuthCtx(ctx, pco)
+	httpr, err := pco.userclient.DefaultApi.OrganizationsOrgIdUsersUserIdDelete(authctx, orgid, userid).Execute()
+	if err != nil {
+		if httpr != nil && httpr.StatusCode == http.StatusNotFound {
+			return nil
+		}
+		return usersBulkHttpError(httpr, err)
+	}
+	httpr.Body.Close()
+	return nil
+}
+
+// adds the user to the new teams, updates the changed memberships and removes the user from the teams it should not be member of anymore
+func updateUsersBulkTeams(ctx context.Context, pco *ProviderConfOutput, orgid string, userid string, old map[string]string, new map[string]string) error {
+	authctx := getTeamMembersAuthCtx(ctx, pco)
+	errs := make([]string, 0)
+	for teamid, membershiptype := range new {
+		if t, ok := old[teamid]; ok && strings.EqualFold(t, membershiptype) {
+			continue
+		}
+		body := team_members.NewTeamMemberPutBodyWithDefaults()
+		body.SetMembershipType(membershiptype)
+		httpr, err := pco.teammembersclient.DefaultApi.OrganizationsOrgIdTeamsTeamIdMembersUserIdPut(authctx, orgid, teamid, userid).TeamMemberPutBody(*body).Execute()
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("unable to add user to team %s: %s", teamid, usersBulkHttpError(httpr, err)))
+			continue
+		}
+		httpr.Body.Close()
+	}
+	for teamid := range old {
+		if _, ok := new[teamid]; ok {
+			continue
+		}
+		httpr, err := pco.teammembersclient.DefaultApi.OrganizationsOrgIdTeamsTeamIdMembersUserIdDelete(authctx, orgid, teamid, userid).Execute()
+		if err != nil && (httpr == nil || httpr.StatusCode != http.StatusNotFound) {
+			errs = append(errs, fmt.Sprintf("unable to remove user from team %s: %s", teamid, usersBulkHttpError(httpr, err)))
+			continue
+		}
+		if httpr != nil {
+			httpr.Body.Close()
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// runs the given tasks by batches of the given size, tasks of the same batch run concurrently
+func runUsersBulkTasks(tasks []func() usersBulkResult, batchsize int) []usersBulkResult {
+	results := make([]usersBulkResult, len(tasks))
+	for start := 0; start < len(tasks); start += batchsize {
+		end := start + batchsize
+		if end > len(tasks) {
+			end = len(tasks)
+		}
+		var wg sync.WaitGroup
+		for i := start; i < end; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				results[i] = tasks[i]()
+			}(i)
+		}
+		wg.Wait()
+	}
+	return results
+}
+
+/*
+Parses the desired users from either the list of users, the CSV document or the JSON document.
+Returns the users indexed by username
+*/
+func parseUsersBulkEntries(users []interface{}, csvdoc string, jsondoc string) (map[string]usersBulkEntry, error) {
+	var list []usersBulkEntry
+	var err error
+	if len(users) > 0 {
+		list = parseUsersBulkList(users)
+	} else if strings.TrimSpace(csvdoc) != "" {
+		list, err = parseUsersBulkCSV(csvdoc)
+	} else if strings.TrimSpace(jsondoc) != "" {
+		list, err = parseUsersBulkJSON(jsondoc)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make(map[string]usersBulkEntry)
+	for i, entry := range list {
+		if entry.Username == "" {
+			return nil, fmt.Errorf("user at position %d has no username", i)
+		}
+		if _, ok := entries[entry.Username]; ok {
+			return nil, fmt.Errorf("username %s is declared more than once", entry.Username)
+		}
+		entries[entry.Username] = entry
+	}
+	return entries, nil
+}
+
+func parseUsersBulkList(users []interface{}) []usersBulkEntry {
+	list := make([]usersBulkEntry, len(users))
+	for i, item := range users {
+		u := item.(map[string]interface{})
+		entry := usersBulkEntry{
+			Username:    u["username"].(string),
+			FirstName:   u["first_name"].(string),
+			LastName:    u["last_name"].(string),
+			Email:       u["email"].(string),
+			PhoneNumber: u["phone_number"].(string),
+			Password:    u["password"].(string),
+			Teams:       make(map[string]string),
+		}
+		if teams, ok := u["teams"].(*schema.Set); ok {
+			for _, t := range teams.List() {
+				team := t.(map[string]interface{})
+				entry.Teams[team["team_id"].(string)] = team["membership_type"].(string)
+			}
+		}
+		list[i] = entry
+	}
+	return list
+}
+
+func parseUsersBulkCSV(doc string) ([]usersBulkEntry, error) {
+	reader := csv.NewReader(strings.NewReader(doc))
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the CSV header: %s", err)
+	}
+	columns := make(map[string]int)
+	for i, h := range header {
+		columns[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for _, required := range []string{"username", "first_name", "last_name", "email"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("the CSV header is missing the %s column", required)
+		}
+	}
+
+	list := make([]usersBulkEntry, 0)
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to read CSV line %d: %s", line, err)
+		}
+		get := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		entry := usersBulkEntry{
+			Username:    get("username"),
+			FirstName:   get("first_name"),
+			LastName:    get("last_name"),
+			Email:       get("email"),
+			PhoneNumber: get("phone_number"),
+			Password:    get("password"),
+			Teams:       make(map[string]string),
+		}
+		for _, t := range strings.Split(get("teams"), ";") {
+			t = strings.TrimSpace(t)
+			if t == "" {
+				continue
+			}
+			membershiptype := "member"
+			if i := strings.Index(t, ":"); i >= 0 {
+				t, membershiptype = t[:i], t[i+1:]
+			}
+			if !StringInSlice([]string{"member", "maintainer"}, membershiptype, true) {
+				return nil, fmt.Errorf("invalid membership type %s for team %s on CSV line %d", membershiptype, t, line)
+			}
+			entry.Teams[t] = membershiptype
+		}
+		list = append(list, entry)
+	}
+	return list, nil
+}
+
+func parseUsersBulkJSON(doc string) ([]usersBulkEntry, error) {
+	var items []struct {
+		usersBulkEntry
+		Teams []struct {
+			TeamId         string `json:"team_id"`
+			MembershipType string `json:"membership_type"`
+		} `json:"teams"`
+	}
+	if err := json.Unmarshal([]byte(doc), &items); err != nil {
+		return nil, fmt.Errorf("unable to parse the JSON document: %s", err)
+	}
+	list := make([]usersBulkEntry, len(items))
+	for i, item := range items {
+		entry := item.usersBulkEntry
+		entry.Teams = make(map[string]string)
+		for _, t := range item.Teams {
+			membershiptype := t.MembershipType
+			if membershiptype == "" {
+				membershiptype = "member"
+			}
+			if !StringInSlice([]string{"member", "maintainer"}, membershiptype, true) {
+				return nil, fmt.Errorf("invalid membership type %s for team %s of user %s", membershiptype, t.TeamId, entry.Username)
+			}
+			entry.Teams[t.TeamId] = membershiptype
+		}
+		list[i] = entry
+	}
+	return list, nil
+}
+
+func newUsersBulkUserPostBody(entry usersBulkEntry) *user.UserPostBody {
+	body := new(user.UserPostBody)
+	body.SetUsername(entry.Username)
+	body.SetFirstName(entry.FirstName)
+	body.SetLastName(entry.LastName)
+	body.SetEmail(entry.Email)
+	if entry.PhoneNumber != "" {
+		body.SetPhoneNumber(entry.PhoneNumber)
+	}
+	if entry.Password != "" {
+		body.SetPassword(entry.Password)
+	}
+	return body
+}
+
+func newUsersBulkUserPutBody(entry usersBulkEntry) *user.UserPutBody {
+	body := new(user.UserPutBody)
+	body.SetUsername(entry.Username)
+	body.SetFirstName(entry.FirstName)
+	body.SetLastName(entry.LastName)
+	body.SetEmail(entry.Email)
+	if entry.PhoneNumber != "" {
+		body.SetPhoneNumber(entry.PhoneNumber)
+	}
+	return body
+}
+
+// compares the user's attributes that can be updated
+func (e usersBulkEntry) equalsAttributes(o usersBulkEntry) bool {
+	return e.Username == o.Username && e.FirstName == o.FirstName && e.LastName == o.LastName &&
+		e.Email == o.Email && e.PhoneNumber == o.PhoneNumber
+}
+
+func (e usersBulkEntry) equals(o usersBulkEntry) bool {
+	if !e.equalsAttributes(o) || len(e.Teams) != len(o.Teams) {
+		return false
+	}
+	for teamid, membershiptype := range e.Teams {
+		if t, ok := o.Teams[teamid]; !ok || !strings.EqualFold(t, membershiptype) {
+			return false
+		}
+	}
+	return true
+}
+
+// checks whether the remote user matches the desired entry
+func equalUsersBulkUser(entry usersBulkEntry, usr *user.User) bool {
+	return entry.FirstName == usr.GetFirstName() && entry.LastName == usr.GetLastName() &&
+		strings.EqualFold(entry.Email, usr.GetEmail()) && (entry.PhoneNumber == "" || entry.PhoneNumber == usr.GetPhoneNumber())
+}
+
+// returns true if the user which username is at the given key has already been provisioned by this resource
+func isUsersBulkUserProvisioned(d *schema.ResourceData, usernamekey string) bool {
+	username, _ := d.Get(usernamekey).(string)
+	_, ok := d.Get("user_ids").(map[string]interface{})[username]
+	return ok
+}
+
+// removes the passwords from the users list and documents kept in the state
+func scrubUsersBulkPasswords(d *schema.ResourceData) error {
+	if users := d.Get("users").([]interface{}); len(users) > 0 {
+		list := make([]interface{}, len(users))
+		for i, item := range users {
+			u := make(map[string]interface{})
+			for k, v := range item.(map[string]interface{}) {
+				u[k] = v
+			}
+			if teams, ok := u["teams"].(*schema.Set); ok {
+				u["teams"] = teams.List()
+			}
+			u["password"] = ""
+			list[i] = u
+		}
+		if err := d.Set("users", list); err != nil {
+			return err
+		}
+	}
+	if doc := d.Get("users_csv").(string); doc != "" {
+		if err := d.Set("users_csv", redactUsersBulkCSV(doc)); err != nil {
+			return err
+		}
+	}
+	if doc := d.Get("users_json").(string); doc != "" {
+		if err := d.Set("users_json", redactUsersBulkJSON(doc)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// empties the password column of the given CSV document, the document is returned as is if it can't be parsed
+func redactUsersBulkCSV(doc string) string {
+	reader := csv.NewReader(strings.NewReader(doc))
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil || len(records) == 0 {
+		return doc
+	}
+	column := -1
+	for i, h := range records[0] {
+		if strings.ToLower(strings.TrimSpace(h)) == "password" {
+			column = i
+		}
+	}
+	if column < 0 {
+		return doc
+	}
+	for _, record := range records[1:] {
+		if column < len(record) {
+			record[column] = ""
+		}
+	}
+	var b strings.Builder
+	writer := csv.NewWriter(&b)
+	if err := writer.WriteAll(records); err != nil {
+		return doc
+	}
+	return b.String()
+}
+
+// removes the password attribute of each user of the given JSON document, the document is returned as is if it can't be parsed
+func redactUsersBulkJSON(doc string) string {
+	var items []map[string]interface{}
+	if err := json.Unmarshal([]byte(doc), &items); err != nil {
+		return doc
+	}
+	for _, item := range items {
+		delete(item, "password")
+	}
+	b, err := json.Marshal(items)
+	if err != nil {
+		return doc
+	}
+	return string(b)
+}
+
+func usersBulkHttpError(httpr *http.Response, err error) error {
+	if httpr != nil {
+		b, _ := ioutil.ReadAll(httpr.Body)
+		httpr.Body.Close()
+		if len(b) > 0 {
+			return fmt.Errorf("%s", string(b))
+		}
+	}
+	return err
+}
+
+// returns the keys of the given map sorted alphabetically
+func sortedMapKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -89,7 +89,7 @@ func resourceVPC() *schema.Resource {
 				Elem: &schema.Schema{
 					Type: schema.TypeString,
 				},
-				Description: "A list of CloudHub environments to associate to this vpc.",
+				Description: "A list of CloudHub environments to associate to this vpc. The environments associated outside of this resource are removed, list this vpc in the skip_vpc_ids of anypoint_env_clone.",
 			},
 			"owner_id": {
 				Type:        schema.TypeString,
@@ -159,6 +159,9 @@ func resourceVPC() *schema.Resource {
 				},
 			},
 		},
+		Importer: &schema.ResourceImporter{
+			StateContext: importStateCompositeId("org_id"),
+		},
 	}
 }
 
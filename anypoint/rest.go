@@ -0,0 +1,124 @@
+package anypoint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+// serializes the read-modify-write cycles performed by this provider on the same list, indexed by the list's description
+var readModifyWriteLocks sync.Map
+
+// base urls of the anypoint control planes indexed by server index (see cplane2serverindex)
+var anypointBaseUrls = [...]string{
+	"https://anypoint.mulesoft.com",
+	"https://eu1.anypoint.mulesoft.com",
+	"https://gov.anypoint.mulesoft.com",
+}
+
+/*
+Sends a request to an anypoint platform endpoint that is not yet covered by the anypoint client library.
+The body, if not nil, is sent as JSON and the JSON response is decoded into result if not nil.
+The response's body is consumed and closed, when the request fails the returned error contains the response's body.
+*/
+func doAnypointRequest(ctx context.Context, pco *ProviderConfOutput, method string, path string, body interface{}, result interface{}) (*http.Response, error) {
+	return doAnypointRequestWithHeaders(ctx, pco, method, path, nil, body, result)
+}
+
+/*
+Same as doAnypointRequest, the given headers are added to the request.
+Some anypoint endpoints select the organization or environment through headers (X-ANYPNT-ORG-ID, X-ANYPNT-ENV-ID).
+*/
+func doAnypointRequestWithHeaders(ctx context.Context, pco *ProviderConfOutput, method string, path string, headers map[string]string, body interface{}, result interface{}) (*http.Response, error) {
+	if pco.server_index < 0 || pco.server_index >= len(anypointBaseUrls) {
+		return nil, fmt.Errorf("unknown control plane server index %d", pco.server_index)
+	}
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("unable to encode request body of %s %s: %s", method, path, err)
+		}
+		reader = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, anypointBaseUrls[pco.server_index]+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+pco.access_token)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	httpr, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return httpr, err
+	}
+	defer httpr.Body.Close()
+	b, err := ioutil.ReadAll(httpr.Body)
+	if err != nil {
+		return httpr, err
+	}
+	if httpr.StatusCode >= 300 {
+		return httpr, fmt.Errorf("%s %s failed with status %d: %s", method, path, httpr.StatusCode, string(b))
+	}
+	if result != nil && len(b) > 0 {
+		if err := json.Unmarshal(b, result); err != nil {
+			return httpr, fmt.Errorf("unable to decode response of %s %s: %s", method, path, err)
+		}
+	}
+	return httpr, nil
+}
+
+/*
+Performs a read-modify-write cycle of a list held by the platform, the list is described by the given key, e.g. "team {id} groupmappings".
+The write function reads the current list, modifies it and writes it back, the verify function reads the written list back and checks the modification.
+The cycles on the same list are serialized within the provider and the whole cycle is retried if a concurrent modification is detected.
+*/
+func readModifyWrite(ctx context.Context, key string, timeout time.Duration, write func() *resource.RetryError, verify func() (bool, error)) error {
+	l, _ := readModifyWriteLocks.LoadOrStore(key, &sync.Mutex{})
+	lock := l.(*sync.Mutex)
+	lock.Lock()
+	defer lock.Unlock()
+
+	return resource.RetryContext(ctx, timeout, func() *resource.RetryError {
+		if err := write(); err != nil {
+			return err
+		}
+		ok, err := verify()
+		if err != nil {
+			return resource.NonRetryableError(err)
+		}
+		if !ok {
+			return resource.RetryableError(fmt.Errorf("%s have been modified concurrently", key))
+		}
+		return nil
+	})
+}
+
+// converts the error of a read-modify-write cycle's write, the conflicts reported by the platform are retried
+func readModifyWriteError(key string, httpr *http.Response, err error) *resource.RetryError {
+	var details string
+	if httpr != nil {
+		b, _ := ioutil.ReadAll(httpr.Body)
+		details = string(b)
+	} else {
+		details = err.Error()
+	}
+	if httpr != nil && (httpr.StatusCode == http.StatusConflict || httpr.StatusCode == http.StatusPreconditionFailed) {
+		return resource.RetryableError(fmt.Errorf("concurrent modification of %s\n details: %s", key, details))
+	}
+	return resource.NonRetryableError(fmt.Errorf("unable to update %s\n details: %s", key, details))
+}
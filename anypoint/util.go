@@ -1,8 +1,10 @@
 package anypoint
 
 import (
+	"context"
 	"crypto/sha1"
 	"encoding/hex"
+	"fmt"
 	"reflect"
 	"sort"
 	"strings"
@@ -104,6 +106,23 @@ func FilterMapList(list []interface{}, filter func(map[string]interface{}) bool)
 	return result
 }
 
+// returns the offset and the page size (limit) set in the given search parameters block of a data source.
+// the given default limit is used if the page size is not set
+func getPaginationParams(params *schema.Set, defaultLimit int) (int, int) {
+	offset, limit := 0, defaultLimit
+	if params == nil || params.Len() == 0 {
+		return offset, limit
+	}
+	opts := params.List()[0].(map[string]interface{})
+	if v, ok := opts["offset"]; ok {
+		offset = v.(int)
+	}
+	if v, ok := opts["limit"]; ok && v.(int) > 0 {
+		limit = v.(int)
+	}
+	return offset, limit
+}
+
 // compares diffing for optional values, if the new value is equal to the initial value (that is the default value)
 // returns true if the attribute has the same value as the initial or if the new and old value are the same which needs no updaten false otherwise.
 func DiffSuppressFunc4OptionalPrimitives(k, old, new string, d *schema.ResourceData, initial string) bool {
@@ -143,3 +162,24 @@ func ComposeResourceId(elem []string) string {
 func DecomposeResourceId(id string) []string {
 	return strings.Split(id, COMPOSITE_ID_SEPARATOR)
 }
+
+/*
+Returns an importer for the resources which read depends on attributes other than their id.
+The import id is composed of the values of the given attributes followed by the resource id, separated by COMPOSITE_ID_SEPARATOR.
+*/
+func importStateCompositeId(attrs ...string) schema.StateContextFunc {
+	format := ComposeResourceId(append(append([]string{}, attrs...), "id"))
+	return func(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+		s := DecomposeResourceId(d.Id())
+		if len(s) != len(attrs)+1 {
+			return nil, fmt.Errorf("unexpected import id %q, expected %s", d.Id(), format)
+		}
+		for i, attr := range attrs {
+			if err := d.Set(attr, s[i]); err != nil {
+				return nil, err
+			}
+		}
+		d.SetId(s[len(attrs)])
+		return []*schema.ResourceData{d}, nil
+	}
+}
@@ -0,0 +1,13 @@
+data "anypoint_bgs" "retail_prod" {
+  org_id     = var.retail_org   # the walk starts from this business group
+  name_regex = "-prod$"         # optional, filters on the business group name
+  min_depth  = 1                # optional, excludes the root business group
+  max_depth  = 0                # optional, 0 (default) walks the whole tree
+}
+
+output "retail_prod_environments" {
+  value = {
+    for bg in data.anypoint_bgs.retail_prod.business_groups :
+    bg.path => [for env in bg.environments : env.name]
+  }
+}
@@ -0,0 +1,7 @@
+data "anypoint_connected_app_scopes" "env_scopes" {
+  context = "env"   # optional, one of none, org or env
+}
+
+output "env_scopes" {
+  value = data.anypoint_connected_app_scopes.env_scopes.scopes
+}
@@ -1,4 +1,9 @@
 data "anypoint_env" "env" {
   org_id = "xxxx-xxx-xxx"   # the business group id
   id     = "xxxx-xxx-xxxx"  # environment id
-}
\ No newline at end of file
+}
+
+data "anypoint_env" "prod" {
+  org_id = "xxxx-xxx-xxx"   # the business group id
+  name   = "Production"     # environment name, can be used instead of the id
+}
@@ -0,0 +1,9 @@
+data "anypoint_envs" "sandboxes" {
+  org_id     = "xxxx-xxx-xxx"   # the business group id
+  type       = "sandbox"        # optional, filters on the environment type
+  name_regex = "^UAT"           # optional, filters on the environment name
+}
+
+output "sandbox_ids" {
+  value = data.anypoint_envs.sandboxes.ids
+}
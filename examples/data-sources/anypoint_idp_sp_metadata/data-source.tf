@@ -0,0 +1,8 @@
+data "anypoint_idp_sp_metadata" "sp" {
+  org_id      = "xxxx-xxx-xxx"   # the business group id
+  provider_id = "xxxx-xxx-xxxx"  # the SAML provider id
+}
+
+output "sp_metadata" {
+  value = data.anypoint_idp_sp_metadata.sp.metadata_xml
+}
@@ -0,0 +1,11 @@
+data "anypoint_org_usage" "usage" {
+  org_id    = var.root_org
+  max_depth = 2         # optional, 0 (default) reports the whole hierarchy
+}
+
+output "production_vcores_available" {
+  value = {
+    for bg in data.anypoint_org_usage.usage.business_groups :
+    bg.name => bg.vcores_production[0].available
+  }
+}
@@ -1,9 +1,11 @@
  data "anypoint_users" "users" {
    org_id = "YOUR_ORG_ID"
    params {
-     offset = 0         # page number
-     limit  = 200       # number of users per page
-     type   = "all"     # users type
+     limit            = 200                     # number of users per request, all pages are loaded
+     type             = "all"                   # users type
+     name             = "smith"                 # first, last or full name contains (case insensitive)
+     enabled          = "true"                  # all, true or false
+     last_login_after = "2023-01-01T00:00:00Z"  # logged in after the given time
    }
  }
 
@@ -1,5 +1,6 @@
 provider "anypoint" {
-  # use either username/pwd or client id/secret to connect to the platform
+  # use either username/pwd, client id/secret, client id/JWT private key
+  # or client id/OIDC token to connect to the platform
 
   username = var.username               # optionally use ANYPOINT_USERNAME env var
   password = var.password               # optionally use ANYPOINT_PASSWORD env var
@@ -9,7 +10,24 @@ provider "anypoint" {
 
   access_token  = var.access_token      # optionally use ANYPOINT_ACCESS_TOKEN env var
 
+  # JWT bearer: the assertion is signed with a private key whose certificate is registered in the connected app
+  jwt_private_key_file = var.jwt_private_key_file   # optionally use ANYPOINT_JWT_PRIVATE_KEY_FILE env var or jwt_private_key (ANYPOINT_JWT_PRIVATE_KEY)
+  jwt_subject          = var.jwt_subject            # optionally use ANYPOINT_JWT_SUBJECT env var
+
+  # OIDC token exchange: the CI identity token is exchanged for an access token
+  oidc_token_file = var.oidc_token_file # optionally use ANYPOINT_OIDC_TOKEN_FILE env var or oidc_token (ANYPOINT_OIDC_TOKEN)
+
+  # credentials can also be loaded from a profile of a credentials file, for instance:
+  #   [dev]
+  #   client_id     = xxx
+  #   client_secret = xxx
+  #   cplane        = eu
+  #   org_id        = xxx   # only used as the default business group of the export subcommand
+  # the profile is only used when no credentials are set in the provider or through ANYPOINT_* env vars
+  profile          = var.profile           # optionally use ANYPOINT_PROFILE env var, defaults to 'default'
+  credentials_file = var.credentials_file  # optionally use ANYPOINT_CREDENTIALS_FILE env var, defaults to '~/.anypoint/credentials'
+
   # You may need to change the anypoint control plane: use 'eu' or 'us'
-  # by default the control plane is 'us'
+  # by default the control plane is the profile's or 'us'
   cplane= var.cplane                    # optionnaly use ANYPOINT_CPLANE env var
 }
\ No newline at end of file
@@ -13,6 +13,26 @@ variable "client_secret" {
 variable "access_token" {
 }
 
+variable "jwt_private_key_file" {
+  default = null
+}
+
+variable "jwt_subject" {
+  default = null
+}
+
+variable "oidc_token_file" {
+  default = null
+}
+
+variable "profile" {
+  default = null
+}
+
+variable "credentials_file" {
+  default = null
+}
+
 variable "cplane" {
-  default = "us"
+  default = null
 }
\ No newline at end of file
@@ -2,14 +2,33 @@ resource "anypoint_bg" "bg" {
   name = "YOUR_BG_NAME"
   parent_organization_id = var.root_org
   owner_id = var.owner_id
-  entitlements_createsuborgs = true
-  entitlements_createenvironments = true
-  entitlements_globaldeployment = true
-  entitlements_vcoresproduction_assigned = 0
-  entitlements_vcoressandbox_assigned = 0
-  entitlements_vcoresdesign_assigned = 0
-  entitlements_staticips_assigned = 0
-  entitlements_vpcs_assigned = 1
-  entitlements_loadbalancer_assigned = 0
-  entitlements_vpns_assigned = 1
-}
\ No newline at end of file
+
+  entitlements {
+    create_sub_orgs     = true
+    create_environments = true
+    global_deployment   = true
+
+    vcores {
+      production_assigned = 0
+      sandbox_assigned    = 0
+      design_assigned     = 0
+    }
+
+    network {
+      static_ips_assigned     = 0
+      vpcs_assigned           = 1
+      vpns_assigned           = 1
+      load_balancers_assigned = 0
+    }
+
+    mq {
+      messages_base = 1000000
+      requests_base = 1000000
+    }
+  }
+
+  properties = {               # only these keys are managed, the other properties are left untouched
+    cost_center = "CC-1234"
+    team        = "integration"
+  }
+}
@@ -0,0 +1,5 @@
+resource "anypoint_bg_property" "owner" {
+  org_id = anypoint_bg.bg.id    # the business group id
+  key    = "owner"              # the property key
+  value  = "jane.doe@example.com"
+}
@@ -55,4 +55,17 @@ resource "anypoint_connected_app" "my_conn_app_behalf_of_user" {
     scope {
         scope = "read:full"
     }
-}
\ No newline at end of file
+}
+
+resource "anypoint_connected_app" "my_conn_app_rotated" {
+    name = "rotated secret"
+    grant_types = ["client_credentials"]
+    audience = "internal"
+
+    rotate_after_days = 90
+    rotation_grace_period_days = 7 # optional, keeps the previous secret visible for 7 days
+
+    scope {
+        scope = "profile"
+    }
+}
@@ -0,0 +1,16 @@
+resource "anypoint_connected_app_key" "jwt_key" {
+  connected_app_id = anypoint_connected_app.my_conn_app_behalf_of_user.id
+  algorithm        = "RSA"
+  rsa_bits         = 2048
+  validity_days    = 365
+
+  # the new key is registered before the previous one is removed when the key is replaced
+  lifecycle {
+    create_before_destroy = true
+  }
+}
+
+output "jwt_private_key" {
+  value     = anypoint_connected_app_key.jwt_key.private_key_pem
+  sensitive = true
+}
@@ -0,0 +1,20 @@
+variable "client_uri" {
+  default = "https://mysite.com" # url describing the connected app
+}
+
+resource "anypoint_connected_app" "my_conn_app_behalf_of_user" {
+  name = "behalf of user"
+  grant_types = [
+    "urn:ietf:params:oauth:grant-type:jwt-bearer"
+  ]
+
+  audience   = "everyone"
+  client_uri = var.client_uri
+
+  # the keys are registered using anypoint_connected_app_key
+  public_keys_managed_separately = true
+
+  scope {
+    scope = "full"
+  }
+}
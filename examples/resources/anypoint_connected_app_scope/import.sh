@@ -0,0 +1,8 @@
+# In order for the import to work, you should provide a ID composed of the following:
+#  {CONNECTED_APP_ID}/{SCOPE}/{ORG_ID}/{ENV_ID}
+# ORG_ID and ENV_ID are left empty when not required by the scope
+
+terraform import \
+  -var-file params.tfvars.json \    #variables file
+  anypoint_connected_app_scope.ci_view_env \                #resource name
+  2a5e1e56e4a14ad7b3b5e3c6f2e8b7a1/view:environment/aa1f55d6-213d-4f60-845c-201282484cd1/7074fcdd-9b23-4ab6-97r8-5db5f4adf17d    #resource ID
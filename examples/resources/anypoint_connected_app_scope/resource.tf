@@ -0,0 +1,12 @@
+resource "anypoint_connected_app_scope" "ci_view_env" {
+  connected_app_id = anypoint_connected_app.my_conn_app_its_own_behalf.id
+  scope            = "view:environment"
+  org_id           = var.org_id
+  env_id           = var.env_id
+}
+
+resource "anypoint_connected_app_scope" "ci_audit_logs" {
+  connected_app_id = anypoint_connected_app.my_conn_app_its_own_behalf.id
+  scope            = "read:audit_logs"
+  org_id           = var.org_id
+}
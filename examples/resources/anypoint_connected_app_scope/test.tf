@@ -0,0 +1,16 @@
+variable "org_id" {
+  default = "4ddb9686-45ac-4e35-81e0-8c83112480c0" # existing Organization/Business Group ID
+}
+
+variable "env_id" {
+  default = "772286a8-bd54-4c71-a09d-0de1b7fee7d2" # existing Environment ID within above Organization/Business Group
+}
+
+resource "anypoint_connected_app" "my_conn_app_its_own_behalf" {
+  name        = "its own behalf"
+  grant_types = ["client_credentials"]
+  audience    = "internal"
+
+  # the scopes are granted using anypoint_connected_app_scope
+  scopes_managed_separately = true
+}
@@ -0,0 +1,7 @@
+# In order for the import to work, you should provide a ID composed of the following:
+#  {ORG_ID}/{VPC_ID}/{DLB_ID}
+
+terraform import \
+  -var-file params.tfvars.json \    #variables file
+  anypoint_dlb.dlb \                #resource name
+  aa1f55d6-213d-4f60-845c-201282484cd1/vpc-0aea8ee4ff5ed4f15/5f2e6b7a0c1d4e0012345678    #resource ID
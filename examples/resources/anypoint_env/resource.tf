@@ -3,3 +3,10 @@ resource "anypoint_env" "env" {
   name = "DEV"                  # environment name
   type = "sandbox"              # environment type : sandbox/production
 }
+
+resource "anypoint_env" "prod" {
+  org_id = anypoint_bg.bg.id
+  name = "PROD"
+  type = "production"           # production environments are protected against deletion by default
+  deletion_protection = true
+}
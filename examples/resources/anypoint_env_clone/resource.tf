@@ -0,0 +1,8 @@
+resource "anypoint_env_clone" "uat2" {
+  org_id        = anypoint_bg.bg.id           # business group of the source environment
+  source_env_id = anypoint_env.uat.id         # environment to replicate
+  name          = "UAT2"                      # name of the cloned environment
+  regions       = ["us-east-1", "eu-west-1"]  # optional, MQ regions to replicate, defaults to all
+  clone_team_roles = false                    # optional, all the categories are replicated by default
+  skip_vpc_ids  = [var.vpc_id]                # optional, VPCs managed by anypoint_vpc, not associated by the clone
+}
@@ -0,0 +1,28 @@
+variable "root_org" {
+  default = "xx1f55d6-213d-4f60-845c-207286484cd1"
+}
+
+variable "owner_id" {
+  default = "18f23771-c78a-4be2-af8f-1bae66f43942"
+}
+
+variable "vpc_id" {
+  default = "vpc-0aea2f2e4ec7b3c2a" # existing VPC managed by anypoint_vpc
+}
+
+resource "anypoint_bg" "bg" {
+  name = "TEST_BG_TF"
+  parent_organization_id = var.root_org
+  owner_id = var.owner_id
+
+  entitlements {
+    create_sub_orgs     = true
+    create_environments = true
+  }
+}
+
+resource "anypoint_env" "uat" {
+  org_id = anypoint_bg.bg.id
+  name = "UAT"
+  type = "sandbox"
+}
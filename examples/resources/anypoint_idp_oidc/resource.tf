@@ -30,4 +30,18 @@ resource "anypoint_idp_oidc" "example2" {
 
     allow_untrusted_certificates = true
   }
-}
\ No newline at end of file
+}
+
+resource "anypoint_idp_oidc" "example3" {
+  org_id           = var.root_org
+  name             = "openid connect provider 3"
+  issuer_discovery = true
+  oidc_provider {
+    issuer = "http://idp.example.com/auth/realms/master"
+
+    client_credentials_id     = "anypoint-oidc"
+    client_credentials_secret = "63b376f8-3ece-44f6-869c-33fe9022fdc4"
+
+    client_token_endpoint_auth_methods_supported = ["client_secret_basic"]
+  }
+}
@@ -36,4 +36,16 @@ resource "anypoint_idp_saml" "example2" {
   }
   sp_sign_on_url  = "http://idp.example.com/auth/realms/master/protocol/saml"
   sp_sign_out_url = "http://idp.example.com/auth/realms/master/protocol/saml"
-}
\ No newline at end of file
+}
+
+resource "anypoint_idp_saml" "example3" {
+  org_id       = var.root_org
+  name         = "SAML 2.0 provider from metadata"
+  metadata_url = "http://idp.example.com/auth/realms/master/protocol/saml/descriptor"
+  saml {
+    audience = "example3.anypoint.mulesoft.com"
+
+    sp_initiated_sso_enabled  = true
+    idp_initiated_sso_enabled = true
+  }
+}
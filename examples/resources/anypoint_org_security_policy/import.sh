@@ -0,0 +1,8 @@
+# In order for the import to work, you should provide a ID composed of the following:
+#  {ORG_ID}
+# ORG_ID must be the master organization id
+
+terraform import \
+  -var-file params.tfvars.json \    #variables file
+  anypoint_org_security_policy.master \                #resource name
+  aa1f55d6-213d-4f60-845c-201282484cd1    #resource ID
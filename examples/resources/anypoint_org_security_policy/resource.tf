@@ -0,0 +1,17 @@
+resource "anypoint_org_security_policy" "master" {
+  org_id          = var.root_org
+  mfa_required    = "enabled"
+  session_timeout = 30
+
+  password_policy {
+    min_length                 = 12
+    require_special_characters = true
+    expiration_days            = 90
+    history_count              = 5
+  }
+
+  ip_allowlist = [
+    "10.0.0.0/16",
+    "192.168.10.0/24",
+  ]
+}
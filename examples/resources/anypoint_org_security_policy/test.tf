@@ -0,0 +1,3 @@
+variable "root_org" {
+  default = "xx1f55d6-213d-4f60-845c-207286484cd1"
+}
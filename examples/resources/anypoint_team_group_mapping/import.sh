@@ -0,0 +1,7 @@
+# In order for the import to work, you should provide a ID composed of the following:
+#  {ORG_ID}/{TEAM_ID}/{PROVIDER_ID}/{EXTERNAL_GROUP_NAME}
+
+terraform import \
+  -var-file params.tfvars.json \    #variables file
+  anypoint_team_group_mapping.team_gmap \                #resource name
+  aa1f55d6-213d-4f60-845c-201282484cd1/7074fcdd-9b23-4ab6-97r8-5db5f4adf17d/pr01/gr_name01    #resource ID
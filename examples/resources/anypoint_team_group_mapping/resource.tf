@@ -0,0 +1,7 @@
+resource "anypoint_team_group_mapping" "team_gmap" {
+  org_id              = var.root_org
+  team_id             = anypoint_team.team.id
+  external_group_name = "gr_name01"     #the group name in the IDP side
+  provider_id         = "pr01"          #the identity provider id
+  membership_type     = "maintainer"    #enum : member or maintainer
+}
@@ -0,0 +1,7 @@
+# In order for the import to work, you should provide a ID composed of the following:
+#  {ORG_ID}_{TEAM_ID}_roles
+
+terraform import \
+  -var-file params.tfvars.json \      #variables file
+  anypoint_team_roles.team_roles \    #resource name
+  aa1f55d6-213d-4f60-845c-201282484cd1_3f3a7b2c-51d4-4a6e-9c61-0b6f5c1e8d22_roles    #resource ID
@@ -11,4 +11,10 @@ resource "anypoint_user" "user" {
 output "user" {
   value = data.anypoint_user.user
   sensitive = true
-}
\ No newline at end of file
+}
+resource "anypoint_user" "invited_user" {
+  org_id = var.root_org
+  email  = "invitee@provider.com"
+  invite = true          # sends an email invitation instead of creating the user with a password
+  invitation_resend_trigger = "1" # change this value to resend a pending invitation
+}
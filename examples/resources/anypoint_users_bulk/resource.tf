@@ -0,0 +1,30 @@
+resource "anypoint_users_bulk" "users" {
+  org_id     = var.root_org
+  batch_size = 10
+
+  users {
+    username   = "my_unique_username01"
+    first_name = "terraform"
+    last_name  = "provider"
+    email      = "terraform@provider.com"
+    password   = "my_super_secret_pwd"
+
+    teams {
+      team_id         = anypoint_team.team.id
+      membership_type = "maintainer"
+    }
+  }
+}
+
+resource "anypoint_users_bulk" "users_from_csv" {
+  org_id    = var.root_org
+  users_csv = <<-EOT
+    username,first_name,last_name,email,password,teams
+    my_unique_username02,john,doe,john.doe@provider.com,my_super_secret_pwd,${anypoint_team.team.id}:member
+    my_unique_username03,jane,doe,jane.doe@provider.com,my_super_secret_pwd,
+  EOT
+}
+
+output "user_ids" {
+  value = anypoint_users_bulk.users.user_ids
+}
@@ -0,0 +1,7 @@
+# In order for the import to work, you should provide a ID composed of the following:
+#  {ORG_ID}/{VPC_ID}
+
+terraform import \
+  -var-file params.tfvars.json \    #variables file
+  anypoint_vpc.vpc \                #resource name
+  aa1f55d6-213d-4f60-845c-201282484cd1/vpc-0aea8ee4ff5ed4f15    #resource ID
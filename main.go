@@ -4,6 +4,8 @@ import (
 	"context"
 	"flag"
 	"log"
+	"os"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/plugin"
@@ -12,6 +14,11 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		export(os.Args[2:])
+		return
+	}
+
 	var debugMode bool
 
 	flag.BoolVar(&debugMode, "debug", false, "set to true to run the provider with support for debuggers like delve")
@@ -33,3 +40,39 @@ func main() {
 
 	plugin.Serve(opts)
 }
+
+// generates the terraform configuration and import blocks of an existing business group
+func export(args []string) {
+	var opts anypoint.ExportOptions
+	var types string
+
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	fs.StringVar(&opts.OrgId, "org-id", "", "the id of the business group to export, defaults to the credentials profile's org_id")
+	fs.StringVar(&opts.OutputDir, "out", ".", "the directory where the configuration files are written")
+	fs.StringVar(&opts.Profile, "profile", "", "the credentials profile to use")
+	fs.StringVar(&opts.CredentialsFile, "credentials-file", "", "the credentials file the profile is read from")
+	fs.StringVar(&opts.Cplane, "cplane", "", "the anypoint control plane: us, eu or gov")
+	fs.StringVar(&types, "types", "", "comma separated list of the resource types to export, all the supported types by default: "+strings.Join(anypoint.EXPORT_RESOURCE_TYPES, ","))
+	fs.Parse(args)
+
+	for _, t := range strings.Split(types, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			opts.ResourceTypes = append(opts.ResourceTypes, t)
+		}
+	}
+
+	res, err := anypoint.ExportOrg(context.Background(), opts)
+	if res != nil {
+		for _, w := range res.Warnings {
+			log.Printf("[WARN] %s", w)
+		}
+	}
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	for _, t := range anypoint.EXPORT_RESOURCE_TYPES {
+		if n := res.Resources[t]; n > 0 {
+			log.Printf("exported %d %s", n, t)
+		}
+	}
+}